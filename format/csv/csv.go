@@ -14,6 +14,8 @@ import (
 	"github.com/wader/fq/pkg/decode"
 	"github.com/wader/fq/pkg/interp"
 	"github.com/wader/fq/pkg/scalar"
+	"github.com/wader/gojq"
+	"golang.org/x/exp/slices"
 )
 
 //go:embed csv.jq
@@ -73,10 +75,78 @@ func decodeCSV(d *decode.D) any {
 }
 
 type ToCSVOpts struct {
-	Comma string
+	Comma   string
+	Columns []string
+}
+
+// toCSVObjectRows converts rows of objects to a header row followed by array rows.
+// Columns are opts columns or union of all keys, sorted per row in order of first
+// appearance. Nested arrays and objects are JSON encoded.
+func toCSVObjectRows(c []any, columns []string) ([]any, error) {
+	var objs []map[string]any
+	for _, row := range c {
+		o, ok := gojqex.Cast[map[string]any](row)
+		if !ok {
+			return nil, fmt.Errorf("expected all rows to be objects, got %s", gojqex.TypeErrorPreview(row))
+		}
+		objs = append(objs, o)
+	}
+
+	if len(columns) == 0 {
+		seen := map[string]bool{}
+		for _, o := range objs {
+			keys := make([]string, 0, len(o))
+			for k := range o {
+				if !seen[k] {
+					keys = append(keys, k)
+				}
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+
+	header := make([]any, len(columns))
+	for i, k := range columns {
+		header[i] = k
+	}
+	rows := []any{header}
+	for _, o := range objs {
+		row := make([]any, len(columns))
+		for i, k := range columns {
+			v := gojqex.Normalize(o[k])
+			switch v.(type) {
+			case map[string]any, []any:
+				b, err := gojq.Marshal(v)
+				if err != nil {
+					return nil, err
+				}
+				v = string(b)
+			}
+			row[i] = v
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
 }
 
 func toCSV(_ *interp.Interp, c []any, opts ToCSVOpts) any {
+	if len(c) > 0 {
+		if _, ok := gojqex.Cast[map[string]any](c[0]); ok {
+			rows, err := toCSVObjectRows(c, opts.Columns)
+			if err != nil {
+				return err
+			}
+			c = rows
+		} else if len(opts.Columns) > 0 {
+			return fmt.Errorf("columns option requires rows to be objects, got %s", gojqex.TypeErrorPreview(c[0]))
+		}
+	}
+
 	b := &bytes.Buffer{}
 	w := csv.NewWriter(b)
 	if opts.Comma != "" {
@@ -45,7 +45,7 @@ type Probe_Args_In struct {
 
 var (
 	Image          = &decode.Group{Name: "image"}
-	Probe          = &decode.Group{Name: "probe", DefaultInArg: Probe_In{}}
+	Probe          = &decode.Group{Name: "probe", DefaultInArg: Probe_In{}, IsProbe: true}
 	Probe_Args     = &decode.Group{Name: "probe_args", DefaultInArg: Probe_Args_In{}}
 	Link_Frame     = &decode.Group{Name: "link_frame", DefaultInArg: Link_Frame_In{}}   // ex: ethernet
 	INET_Packet    = &decode.Group{Name: "inet_packet", DefaultInArg: INET_Packet_In{}} // ex: ipv4
@@ -367,6 +367,18 @@ type CSV_In struct {
 	Comment string `doc:"Comment line character"`
 }
 
+type TOML_In struct {
+	MaxSize int64 `doc:"Max input size in bytes, 0 for no limit"`
+}
+
+type TOML_Out struct {
+	// datetime type to array of path expressions for values that were datetime literals,
+	// types are datetime, datetime-local, date-local and time-local
+	Datetimes map[string]any
+	// path expressions for arrays of tables that were inline arrays and not [[...]]
+	InlineTableArrays []any
+}
+
 type Bitcoin_Block_In struct {
 	HasHeader bool `doc:"Has blkdat header"`
 }
@@ -86,16 +86,22 @@ func decodeJSON(d *decode.D) any {
 
 type ToJSONOpts struct {
 	Indent int
+	Binary string
 }
 
 // TODO: share with interp code
-func makeEncoder(opts ToJSONOpts) *colorjson.Encoder {
+func makeEncoder(opts ToJSONOpts, optsFn func() (*interp.Options, error)) *colorjson.Encoder {
 	return colorjson.NewEncoder(colorjson.Options{
 		Color:  false,
 		Tab:    false,
 		Indent: opts.Indent,
 		ValueFn: func(v any) (any, error) {
 			switch v := v.(type) {
+			case interp.JQValueEx:
+				if optsFn == nil {
+					return v.JQValueToGoJQ(), nil
+				}
+				return v.JQValueToGoJQEx(optsFn), nil
 			case gojq.JQValue:
 				return v.JQValueToGoJQ(), nil
 			default:
@@ -107,7 +113,17 @@ func makeEncoder(opts ToJSONOpts) *colorjson.Encoder {
 }
 
 func toJSON(_ *interp.Interp, c any, opts ToJSONOpts) any {
-	cj := makeEncoder(opts)
+	// binary is same as bits_format, empty keeps default behavior
+	var optsFn func() (*interp.Options, error)
+	if opts.Binary != "" {
+		bo, err := interp.OptionsFromValue(map[string]any{"bits_format": opts.Binary})
+		if err != nil {
+			return err
+		}
+		optsFn = func() (*interp.Options, error) { return bo, nil }
+	}
+
+	cj := makeEncoder(opts, optsFn)
 	bb := &bytes.Buffer{}
 	if err := cj.Marshal(c, bb); err != nil {
 		return err
@@ -33,7 +33,7 @@ func decodeJSONL(d *decode.D) any {
 }
 
 func toJSONL(i *interp.Interp, c []any) any {
-	cj := makeEncoder(ToJSONOpts{})
+	cj := makeEncoder(ToJSONOpts{}, nil)
 	bb := &bytes.Buffer{}
 
 	for _, v := range c {
@@ -6,10 +6,8 @@ package png
 
 import (
 	"compress/zlib"
-	"hash/crc32"
 
 	"github.com/wader/fq/format"
-	"github.com/wader/fq/pkg/bitio"
 	"github.com/wader/fq/pkg/decode"
 	"github.com/wader/fq/pkg/interp"
 	"github.com/wader/fq/pkg/scalar"
@@ -124,14 +122,18 @@ func pngDecode(d *decode.D) any {
 
 				// TODO: make nicer
 				d.FieldRawLen("compressed", dataLen)
+				compressed := d.FieldGet("compressed")
 				d.SeekRel(-dataLen)
 
 				switch compressionMethod {
 				case compressionDeflate:
-					d.FieldFormatReaderLen("uncompressed", dataLen, zlib.NewReader, decode.FormatFn(func(d *decode.D) any {
+					// corrupt compressed data is still accessible as compressed with the error on it
+					if _, _, err := d.TryFieldFormatReaderLen("uncompressed", dataLen, zlib.NewReader, decode.FormatFn(func(d *decode.D) any {
 						d.FieldUTF8("text", int(d.BitsLeft()/8))
 						return nil
-					}))
+					})); err != nil {
+						compressed.Err = err
+					}
 				default:
 					d.FieldRawLen("data", dataLen)
 				}
@@ -141,11 +143,14 @@ func pngDecode(d *decode.D) any {
 				dataLen := d.BitsLeft()
 
 				d.FieldRawLen("compressed", dataLen)
+				compressed := d.FieldGet("compressed")
 				d.SeekRel(-dataLen)
 
 				switch compressionMethod {
 				case compressionDeflate:
-					d.FieldFormatReaderLen("uncompressed", dataLen, zlib.NewReader, &iccProfileGroup)
+					if _, _, err := d.TryFieldFormatReaderLen("uncompressed", dataLen, zlib.NewReader, &iccProfileGroup); err != nil {
+						compressed.Err = err
+					}
 				default:
 					d.FieldRawLen("data", dataLen)
 				}
@@ -228,9 +233,7 @@ func pngDecode(d *decode.D) any {
 			}
 		})
 
-		chunkCRC := crc32.NewIEEE()
-		d.Copy(chunkCRC, bitio.NewIOReader(d.BitBufRange(crcStartPos, d.Pos()-crcStartPos)))
-		d.FieldU32("crc", d.UintValidateBytes(chunkCRC.Sum(nil)), scalar.UintHex)
+		d.FieldCRC32IEEE("crc", crcStartPos, d.Pos()-crcStartPos, scalar.UintHex)
 	})
 
 	return nil
@@ -0,0 +1,25 @@
+package format_test
+
+import (
+	"testing"
+
+	_ "github.com/wader/fq/format/all"
+	"github.com/wader/fq/pkg/interp"
+)
+
+// TestDependencies makes sure that all groups a format depends on have at
+// least one registered format, ex: forgot to import a format package in all
+func TestDependencies(t *testing.T) {
+	for _, f := range interp.DefaultRegistry.MustAll().Formats {
+		for _, d := range f.Dependencies {
+			for _, g := range d.Groups {
+				if len(g.Formats) == 0 {
+					t.Errorf("%s: depends on group %q with no formats", f.Name, g.Name)
+				}
+			}
+			if len(d.Out.Formats) == 0 {
+				t.Errorf("%s: dependency resolved to no formats", f.Name)
+			}
+		}
+	}
+}
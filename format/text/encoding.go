@@ -21,7 +21,8 @@ var textFS embed.FS
 
 func init() {
 	interp.RegisterFunc0("from_hex", func(_ *interp.Interp, c string) any {
-		b, err := hex.DecodeString(c)
+		// ignore whitespace so that spaced and multi line hex can be pasted
+		b, err := hex.DecodeString(strings.Join(strings.Fields(c), ""))
 		if err != nil {
 			return err
 		}
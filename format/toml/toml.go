@@ -4,8 +4,12 @@ import (
 	"bufio"
 	"bytes"
 	"embed"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"sort"
+	"time"
 	"unicode/utf8"
 
 	"github.com/BurntSushi/toml"
@@ -28,10 +32,13 @@ func init() {
 			ProbeOrder:  format.ProbeOrderTextFuzzy,
 			Groups:      []*decode.Group{format.Probe},
 			DecodeFn:    decodeTOML,
-			Functions:   []string{"_todisplay"},
+			DefaultInArg: format.TOML_In{
+				MaxSize: 0,
+			},
+			Functions: []string{"_todisplay"},
 		})
 	interp.RegisterFS(tomlFS)
-	interp.RegisterFunc0("to_toml", toTOML)
+	interp.RegisterFunc1("_to_toml", toTOML)
 }
 
 func decodeTOMLSeekFirstValidRune(br io.ReadSeeker) error {
@@ -53,8 +60,151 @@ func decodeTOMLSeekFirstValidRune(br io.ReadSeeker) error {
 	return nil
 }
 
+const tomlProbeMinKeys = 2
+
+// tomlHasTable returns true if any root value is a table or an array of tables
+func tomlHasTable(m map[string]any) bool {
+	for _, v := range m {
+		switch v := v.(type) {
+		case map[string]any:
+			return true
+		case []any:
+			if len(v) > 0 {
+				if _, ok := v[0].(map[string]any); ok {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// decodeTOMLParseError fails with error position set to where the parser
+// reported the error and with line, column and snippet in the reason
+func decodeTOMLParseError(d *decode.D, pe toml.ParseError) {
+	start := int64(pe.Position.Start)
+	if start < 0 || start*8 > d.Len() {
+		return
+	}
+	bs := d.BytesRange(0, int(start))
+	column := start - int64(bytes.LastIndexByte(bs, '\n'))
+
+	snippetLen := int64(pe.Position.Len)
+	if snippetLen < 1 {
+		snippetLen = 1
+	}
+	if (start+snippetLen)*8 > d.Len() {
+		snippetLen = d.Len()/8 - start
+	}
+	snippet := d.BytesRange(start*8, int(snippetLen))
+
+	d.SeekAbs(start * 8)
+	d.Fatalf("%s (column %d near %q)", pe.Error(), column, snippet)
+}
+
+// github.com/BurntSushi/toml decodes all temporal types as time.Time and uses
+// special named locations to tell local datetime, date and time apart
+var tomlTimeLocationLayouts = map[string]string{
+	"datetime-local": "2006-01-02T15:04:05.999999999",
+	"date-local":     "2006-01-02",
+	"time-local":     "15:04:05.999999999",
+}
+
+// tomlDatetimeType returns datetime type for a time decoded from TOML
+func tomlDatetimeType(t time.Time) string {
+	if _, ok := tomlTimeLocationLayouts[t.Location().String()]; ok {
+		return t.Location().String()
+	}
+	return "datetime"
+}
+
+// tomlPathKey returns path expression for object key k, ex: .a or .["a b"]
+func tomlPathKey(path string, k string) string {
+	if !tomlPathIdentRe.MatchString(k) {
+		return fmt.Sprintf("%s.[%q]", path, k)
+	}
+	return path + "." + k
+}
+
+// tomlDatetimePaths collects path expressions per datetime type for all datetimes
+func tomlDatetimePaths(path string, v any, datetimes map[string][]string) {
+	switch v := v.(type) {
+	case time.Time:
+		typ := tomlDatetimeType(v)
+		datetimes[typ] = append(datetimes[typ], path)
+	case map[string]any:
+		for k, e := range v {
+			tomlDatetimePaths(tomlPathKey(path, k), e, datetimes)
+		}
+	case []map[string]any:
+		for i, e := range v {
+			tomlDatetimePaths(fmt.Sprintf("%s[%d]", path, i), e, datetimes)
+		}
+	case []any:
+		for i, e := range v {
+			tomlDatetimePaths(fmt.Sprintf("%s[%d]", path, i), e, datetimes)
+		}
+	}
+}
+
+// tomlIsTableArray returns true if array is non-empty and all elements are tables
+func tomlIsTableArray(v []any) bool {
+	for _, e := range v {
+		if _, ok := e.(map[string]any); !ok {
+			return false
+		}
+	}
+	return len(v) > 0
+}
+
+// tomlInlineTableArrayPaths returns path expressions for arrays of tables that were written
+// as inline arrays, ex: a = [{b = 1}], and not using [[...]] syntax
+func tomlInlineTableArrayPaths(path string, key toml.Key, v any, md toml.MetaData) []string {
+	var paths []string
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			paths = append(paths, tomlInlineTableArrayPaths(tomlPathKey(path, k), append(key[0:len(key):len(key)], k), e, md)...)
+		}
+	case []any:
+		// inline arrays are decoded as []any, everything inside is also inline
+		if md.Type(key...) == "Array" && tomlIsTableArray(v) {
+			return []string{path}
+		}
+	case []map[string]any:
+		// key has no index so all tables in an array of tables share metadata
+		for i, e := range v {
+			paths = append(paths, tomlInlineTableArrayPaths(fmt.Sprintf("%s[%d]", path, i), key, e, md)...)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// format datetimes the same way as they are written in TOML
+func tomlNormalizeTime(v any) any {
+	t, ok := v.(time.Time)
+	if !ok {
+		return v
+	}
+	if layout, ok := tomlTimeLocationLayouts[t.Location().String()]; ok {
+		return t.Format(layout)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
 func decodeTOML(d *decode.D) any {
-	bbr := d.RawLen(d.Len())
+	var ti format.TOML_In
+	d.ArgAs(&ti)
+
+	// github.com/BurntSushi/toml reads all input and builds the whole document
+	// in memory so allow to bail out early on huge input
+	if ti.MaxSize > 0 && d.BitsLeft()/8 > ti.MaxSize {
+		d.Fatalf("input size %d bytes exceeds max_size %d", d.BitsLeft()/8, ti.MaxSize)
+	}
+
+	// only read what is left, might be a framed region when decoded as a sub format
+	bbr := d.RawLen(d.BitsLeft())
 	var r any
 
 	br := bitio.NewIOReadSeeker(bbr)
@@ -65,11 +215,33 @@ func decodeTOML(d *decode.D) any {
 		d.Fatalf("%s", err)
 	}
 
-	if _, err := toml.NewDecoder(br).Decode(&r); err != nil {
+	md, err := toml.NewDecoder(br).Decode(&r)
+	if err != nil {
+		var pe toml.ParseError
+		if errors.As(err, &pe) {
+			decodeTOMLParseError(d, pe)
+		}
 		d.Fatalf("%s", err)
 	}
+	// datetimes are strings in the value, keep track of them so to_toml can write them back as literals.
+	// collect before normalize as it might modify r in place
+	datetimes := map[string][]string{}
+	tomlDatetimePaths("", r, datetimes)
+	datetimesOut := map[string]any{}
+	for typ, paths := range datetimes {
+		sort.Strings(paths)
+		var ps []any
+		for _, p := range paths {
+			ps = append(ps, p)
+		}
+		datetimesOut[typ] = ps
+	}
+	var inlineTableArrays []any
+	for _, p := range tomlInlineTableArrayPaths("", nil, r, md) {
+		inlineTableArrays = append(inlineTableArrays, p)
+	}
 	var s scalar.Any
-	s.Actual = gojqex.Normalize(r)
+	s.Actual = gojqex.Normalize(gojqex.NormalizeFn(r, tomlNormalizeTime))
 
 	// TODO: better way to handle that an empty file is valid toml and parsed as an object
 	switch v := s.Actual.(type) {
@@ -77,6 +249,11 @@ func decodeTOML(d *decode.D) any {
 		if len(v) == 0 {
 			d.Fatalf("root object has no values")
 		}
+		// if probing require a table or a few key/values as lots of text is valid toml, ex: "a=1"
+		var pi format.Probe_In
+		if d.ArgAs(&pi) && !tomlHasTable(v) && len(v) < tomlProbeMinKeys {
+			d.Fatalf("no table and less than %d keys", tomlProbeMinKeys)
+		}
 	case []any:
 	default:
 		d.Fatalf("root not object or array")
@@ -85,16 +262,246 @@ func decodeTOML(d *decode.D) any {
 	d.Value.V = &s
 	d.Value.Range.Len = d.Len()
 
-	return nil
+	return format.TOML_Out{
+		Datetimes:         datetimesOut,
+		InlineTableArrays: inlineTableArrays,
+	}
+}
+
+// tomlUnrepresentablePath returns path to first value that can't be encoded as TOML.
+// Note that null object values are skipped by the encoder so only null array
+// elements are unrepresentable.
+func tomlUnrepresentablePath(path string, v any) (string, any, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if p, pv, ok := tomlUnrepresentablePath(tomlPathKey(path, k), v[k]); ok {
+				return p, pv, ok
+			}
+		}
+	case []any:
+		for i, e := range v {
+			ep := fmt.Sprintf("%s[%d]", path, i)
+			if e == nil {
+				return ep, e, true
+			}
+			if p, pv, ok := tomlUnrepresentablePath(ep, e); ok {
+				return p, pv, ok
+			}
+		}
+	}
+	return "", nil, false
 }
 
-func toTOML(_ *interp.Interp, c any) any {
+var tomlPathIdentRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z_0-9]*$`)
+
+type ToTOMLOpts struct {
+	Indent       string `default:"  "`
+	InlineTables bool
+	Datetimes    map[string][]string // from TOML_Out when input is a decoded TOML value
+
+	InlineTableArrays []string // from TOML_Out when input is a decoded TOML value
+}
+
+// tomlDatetimeLiteral is a datetime that is encoded unquoted
+type tomlDatetimeLiteral string
+
+func (t tomlDatetimeLiteral) MarshalTOML() ([]byte, error) { return []byte(t), nil }
+
+// tomlDatetimeLiterals replaces strings at datetimes paths with datetime literals if
+// they are still valid for the datetime type
+func tomlDatetimeLiterals(path string, v any, datetimes map[string]string) any {
+	switch v := v.(type) {
+	case string:
+		typ, ok := datetimes[path]
+		if !ok {
+			return v
+		}
+		layout, ok := tomlTimeLocationLayouts[typ]
+		if !ok {
+			layout = time.RFC3339Nano
+		}
+		if _, err := time.Parse(layout, v); err != nil {
+			return v
+		}
+		return tomlDatetimeLiteral(v)
+	case map[string]any:
+		n := make(map[string]any, len(v))
+		for k, e := range v {
+			n[k] = tomlDatetimeLiterals(tomlPathKey(path, k), e, datetimes)
+		}
+		return n
+	case []any:
+		n := make([]any, len(v))
+		for i, e := range v {
+			n[i] = tomlDatetimeLiterals(fmt.Sprintf("%s[%d]", path, i), e, datetimes)
+		}
+		return n
+	default:
+		return v
+	}
+}
+
+// tomlInlineTable is a table without sub tables that is encoded as an inline table
+type tomlInlineTable map[string]any
+
+func (t tomlInlineTable) MarshalTOML() ([]byte, error) {
+	keys := make([]string, 0, len(t))
+	for k, v := range t {
+		// null object values are skipped the same way as the encoder does
+		if v != nil {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	// encode each key/value as a one key document to get key quoting and value
+	// encoding the same as for non-inline tables
+	b := &bytes.Buffer{}
+	b.WriteString("{")
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		kb := &bytes.Buffer{}
+		if err := toml.NewEncoder(kb).Encode(map[string]any{k: t[k]}); err != nil {
+			return nil, err
+		}
+		b.Write(bytes.TrimSuffix(kb.Bytes(), []byte("\n")))
+	}
+	b.WriteString("}")
+
+	return b.Bytes(), nil
+}
+
+// tomlInlineTables replaces all tables with inline tables
+func tomlInlineTables(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		n := make(tomlInlineTable, len(v))
+		for k, e := range v {
+			n[k] = tomlInlineTables(e)
+		}
+		return n
+	case []any:
+		n := make([]any, len(v))
+		for i, e := range v {
+			n[i] = tomlInlineTables(e)
+		}
+		return n
+	default:
+		return v
+	}
+}
+
+// tomlInlineTableArrays replaces arrays of tables at paths with arrays of inline tables
+func tomlInlineTableArrays(path string, v any, paths map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		n := make(map[string]any, len(v))
+		for k, e := range v {
+			n[k] = tomlInlineTableArrays(tomlPathKey(path, k), e, paths)
+		}
+		return n
+	case []any:
+		if paths[path] {
+			return tomlInlineTables(v)
+		}
+		n := make([]any, len(v))
+		for i, e := range v {
+			n[i] = tomlInlineTableArrays(fmt.Sprintf("%s[%d]", path, i), e, paths)
+		}
+		return n
+	default:
+		return v
+	}
+}
+
+// tomlInlineLeafTables replaces tables that has no sub tables or arrays of tables with inline tables
+func tomlInlineLeafTables(v any) (any, bool) {
+	switch v := v.(type) {
+	case map[string]any:
+		isLeaf := true
+		n := make(map[string]any, len(v))
+		for k, e := range v {
+			ne, eIsTable := tomlInlineLeafTables(e)
+			if eIsTable {
+				isLeaf = false
+			}
+			n[k] = ne
+		}
+		if isLeaf {
+			return tomlInlineTable(n), true
+		}
+		return n, true
+	case []any:
+		n := make([]any, len(v))
+		hasTable := false
+		for i, e := range v {
+			ne, eIsTable := tomlInlineLeafTables(e)
+			if eIsTable {
+				hasTable = true
+			}
+			n[i] = ne
+		}
+		return n, hasTable
+	default:
+		return v, false
+	}
+}
+
+func toTOML(_ *interp.Interp, c any, opts ToTOMLOpts) any {
 	if c == nil {
 		return gojqex.FuncTypeError{Name: "to_toml", V: c}
 	}
 
+	v := gojqex.Normalize(c)
+	m, ok := v.(map[string]any)
+	if !ok {
+		return gojqex.FuncTypeError{Name: "to_toml", V: v}
+	}
+	if p, pv, ok := tomlUnrepresentablePath("", v); ok {
+		return fmt.Errorf("to_toml cannot encode %s at %s", gojqex.TypeErrorPreview(pv), p)
+	}
+
+	if len(opts.Datetimes) > 0 {
+		datetimes := map[string]string{}
+		for typ, paths := range opts.Datetimes {
+			for _, p := range paths {
+				datetimes[p] = typ
+			}
+		}
+		v = tomlDatetimeLiterals("", v, datetimes)
+		m, _ = v.(map[string]any)
+	}
+
+	if len(opts.InlineTableArrays) > 0 {
+		paths := map[string]bool{}
+		for _, p := range opts.InlineTableArrays {
+			paths[p] = true
+		}
+		v = tomlInlineTableArrays("", v, paths)
+		m, _ = v.(map[string]any)
+	}
+
+	if opts.InlineTables {
+		// root is always a document, only inline its values
+		n := make(map[string]any, len(m))
+		for k, e := range m {
+			n[k], _ = tomlInlineLeafTables(e)
+		}
+		v = n
+	}
+
 	b := &bytes.Buffer{}
-	if err := toml.NewEncoder(b).Encode(gojqex.Normalize(c)); err != nil {
+	e := toml.NewEncoder(b)
+	e.Indent = opts.Indent
+	if err := e.Encode(v); err != nil {
 		return err
 	}
 	return b.String()
@@ -0,0 +1,85 @@
+package format_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/wader/fq/format/all"
+	"github.com/wader/fq/pkg/interp"
+)
+
+type toValueTest struct {
+	args   []string
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+}
+
+func (tt *toValueTest) Platform() interp.Platform { return interp.Platform{} }
+func (tt *toValueTest) Stdin() interp.Input {
+	return fuzzTestInput{FileReader: interp.FileReader{R: &bytes.Buffer{}}}
+}
+func (tt *toValueTest) Stdout() interp.Output        { return fuzzTestOutput{tt.stdout} }
+func (tt *toValueTest) Stderr() interp.Output        { return fuzzTestOutput{tt.stderr} }
+func (tt *toValueTest) InterruptChan() chan struct{} { return nil }
+func (tt *toValueTest) Environ() []string            { return nil }
+func (tt *toValueTest) Args() []string               { return tt.args }
+func (tt *toValueTest) ConfigDir() (string, error)   { return "/config", nil }
+func (tt *toValueTest) FS() fs.FS                    { return os.DirFS(".") }
+func (tt *toValueTest) History() ([]string, error)   { return nil, nil }
+func (tt *toValueTest) Readline(opts interp.ReadlineOpts) (string, error) {
+	return "", io.EOF
+}
+
+// recursive zip bomb, decoding it fully uses too much memory
+var toValueSkip = map[string]bool{
+	"zip/testdata/bigzero-zip.zip": true,
+}
+
+// TestToValue probes all test files and makes sure the decode value can be turned
+// into a JSON value. Bits are formatted as md5 to not have to keep large buffers
+// in memory. Inputs that no format can probe are ignored.
+func TestToValue(t *testing.T) {
+	var paths []string
+	if err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || toValueSkip[filepath.ToSlash(path)] || filepath.Base(filepath.Dir(path)) != "testdata" || filepath.Ext(path) == ".fqtest" {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		path := path
+		t.Run(path, func(t *testing.T) {
+			tt := &toValueTest{
+				args:   []string{"fq", "-r", "-o", "bits_format=md5", `tovalue | tojson | "ok"`, path},
+				stdout: &bytes.Buffer{},
+				stderr: &bytes.Buffer{},
+			}
+			q, err := interp.New(tt, interp.DefaultRegistry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = q.Main(context.Background(), tt.Stdout(), "test")
+
+			stderr := tt.stderr.String()
+			if strings.Contains(stderr, "probe: failed to decode") {
+				t.Skip("no format probed")
+			}
+			if tt.stdout.String() != "ok\n" || stderr != "" {
+				t.Errorf("expected ok, got stdout %q stderr %q", tt.stdout.String(), stderr)
+			}
+		})
+	}
+}
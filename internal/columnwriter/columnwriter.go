@@ -150,6 +150,17 @@ func (c BarColumn) FlushLine(w io.Writer, lineNr int, lastColumn bool) error {
 }
 func (c BarColumn) Reset() {}
 
+// DiscardColumn is a column that is not shown, writes are discarded
+type DiscardColumn struct{}
+
+var _ Column = DiscardColumn{}
+
+func (c DiscardColumn) Write(p []byte) (int, error)                              { return len(p), nil }
+func (c DiscardColumn) Lines() int                                               { return 0 }
+func (c DiscardColumn) PreFlush()                                                {}
+func (c DiscardColumn) FlushLine(w io.Writer, lineNr int, lastColumn bool) error { return nil }
+func (c DiscardColumn) Reset()                                                   {}
+
 // Writer maintins multiple column io.Writer:s. On Flush() row align them.
 type Writer struct {
 	Columns []Column
@@ -33,6 +33,9 @@ var ANSI16Table = MakeTable(0x8005, 16)
 var Poly04c11db7Table = MakeTable(0x04c11db7, 32) // TODO: is this IEEE?
 var IEEELETable = MakeTable(0xedb88320, 32)       // TODO: is this IEEE?
 
+// CCITT16Table CRC-16/CCITT polynomial x^16 + x^12 + x^5 + 1, init 0xffff for CCITT-FALSE
+var CCITT16Table = MakeTable(0x1021, 16)
+
 // CRC implements hash.Hash
 type CRC struct {
 	Bits    int
@@ -8,6 +8,7 @@ import (
 
 	"reflect"
 	"regexp"
+	"sort"
 
 	"github.com/wader/fq/internal/bitioex"
 	"github.com/wader/fq/internal/ioex"
@@ -29,15 +30,22 @@ const (
 )
 
 type Options struct {
-	Name        string
-	Description string
-	Force       bool
-	FillGaps    bool
-	IsRoot      bool
-	Range       ranges.Range // if zero use whole buffer
-	InArg       any
-	ParseOptsFn func(init any) any
-	ReadBuf     *[]byte
+	Name           string
+	Description    string
+	Force          bool
+	FillGaps       bool
+	IsRoot         bool
+	Range          ranges.Range // if zero use whole buffer
+	InArg          any
+	ParseOptsFn    func(init any) any
+	ReadBuf        *[]byte
+	MaxDepth       int // max struct/array nesting depth, 0 for no limit
+	MaxFields      int // max number of fields, 0 for no limit
+	MaxFormatDepth int // max nesting depth of probeable formats, 0 for no limit
+
+	depth       int
+	fieldCount  *int
+	formatDepth int
 }
 
 // Decode try decode group and return first success and all other decoder errors
@@ -104,7 +112,23 @@ func decode(ctx context.Context, br bitio.ReaderAtSeeker, group *Group, opts Opt
 			return nil, nil, IOError{Err: err, Op: "BitBufRange", ReadSize: decodeRange.Len, Pos: decodeRange.Start}
 		}
 
-		d := newDecoder(ctx, f, cBR, opts)
+		formatOpts := opts
+		// each top level format attempt has its own count, nested decodes share it
+		if formatOpts.fieldCount == nil {
+			formatOpts.fieldCount = new(int)
+		}
+		if isProbeFormat(f) {
+			formatOpts.formatDepth++
+			if opts.MaxFormatDepth > 0 && formatOpts.formatDepth > opts.MaxFormatDepth {
+				formatsErr.Errs = append(formatsErr.Errs, FormatError{
+					Err:    fmt.Errorf("max format depth %d reached", opts.MaxFormatDepth),
+					Format: f,
+				})
+				continue
+			}
+		}
+		fieldCountStart := formatOpts.fieldCountMark()
+		d := newDecoder(ctx, f, cBR, formatOpts)
 
 		d.inArgs = inArgs
 
@@ -140,6 +164,8 @@ func decode(ctx context.Context, br bitio.ReaderAtSeeker, group *Group, opts Opt
 			}
 
 			if len(group.Formats) != 1 {
+				// value is discarded, don't count its fields
+				formatOpts.fieldCountRollback(fieldCountStart)
 				continue
 			}
 		}
@@ -238,6 +264,12 @@ func (d *D) ArgAs(target any) bool {
 }
 
 func (d *D) fieldDecoder(name string, bitBuf bitio.ReaderAtSeeker, v any) *D {
+	opts := d.Options
+	opts.depth++
+	if opts.MaxDepth != 0 && opts.depth > opts.MaxDepth {
+		panic(LimitError{Reason: fmt.Sprintf("%q exceeds max depth %d", name, opts.MaxDepth), Pos: d.Pos()})
+	}
+
 	return &D{
 		Ctx:    d.Ctx,
 		Endian: d.Endian,
@@ -247,7 +279,7 @@ func (d *D) fieldDecoder(name string, bitBuf bitio.ReaderAtSeeker, v any) *D {
 			Range:      ranges.Range{Start: d.Pos(), Len: 0},
 			RootReader: bitBuf,
 		},
-		Options: d.Options,
+		Options: opts,
 
 		bitBuf:  bitBuf,
 		readBuf: d.readBuf,
@@ -364,7 +396,7 @@ func (d *D) FillGaps(r ranges.Range, namePrefix string) {
 		}
 
 		// TODO: for arrays not great that we just append gap fields
-		d.AddChild(v)
+		d.addChild(v)
 	}
 }
 
@@ -492,6 +524,28 @@ func (d *D) TryPeekBits(nBits int) (uint64, error) {
 	return n, err
 }
 
+// TryPeekUVLQ peeks an unsigned variable-length quantity, returns value and number of bits it would consume
+func (d *D) TryPeekUVLQ() (uint64, int64, error) {
+	start, err := d.bitBuf.SeekBits(0, io.SeekCurrent)
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := d.TryUVLQ()
+	end, _ := d.bitBuf.SeekBits(0, io.SeekCurrent)
+	if _, err := d.bitBuf.SeekBits(start, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	return n, end - start, err
+}
+
+func (d *D) PeekUVLQ() (uint64, int64) {
+	n, nBits, err := d.TryPeekUVLQ()
+	if err != nil {
+		panic(IOError{Err: err, Op: "PeekUVLQ", Pos: d.Pos()})
+	}
+	return n, nBits
+}
+
 func (d *D) TryPeekFind(nBits int, seekBits int64, maxLen int64, fn func(v uint64) bool) (int64, uint64, error) {
 	start, err := d.bitBuf.SeekBits(0, io.SeekCurrent)
 	if err != nil {
@@ -741,7 +795,15 @@ func (d *D) trySeekAbs(pos int64, fns ...func(d *D)) (int64, error) {
 		oldPos = d.Pos()
 	}
 
-	pos, err := d.bitBuf.SeekBits(pos, io.SeekStart)
+	bufLen, err := d.TryLen()
+	if err != nil {
+		return 0, err
+	}
+	if pos < 0 || pos > bufLen {
+		return 0, fmt.Errorf("seek to bit %d in %s outside buffer of %d bits", pos, d.Value.path(), bufLen)
+	}
+
+	pos, err = d.bitBuf.SeekBits(pos, io.SeekStart)
 	if err != nil {
 		return 0, err
 	}
@@ -791,6 +853,32 @@ func (d *D) SeekAbs(pos int64, fns ...func(d *D)) int64 {
 }
 
 func (d *D) AddChild(v *Value) {
+	if d.Options.MaxFields != 0 && d.Options.fieldCount != nil {
+		*d.Options.fieldCount++
+		if *d.Options.fieldCount > d.Options.MaxFields {
+			panic(LimitError{Reason: fmt.Sprintf("%q exceeds max fields %d", v.Name, d.Options.MaxFields), Pos: d.Pos()})
+		}
+	}
+	d.addChild(v)
+}
+
+// fieldCountMark returns current field count so that fields added by a trial decode
+// that is discarded can be uncounted using fieldCountRollback
+func (o Options) fieldCountMark() int {
+	if o.fieldCount == nil {
+		return 0
+	}
+	return *o.fieldCount
+}
+
+func (o Options) fieldCountRollback(n int) {
+	if o.fieldCount != nil {
+		*o.fieldCount = n
+	}
+}
+
+// addChild adds v without counting it, used for gaps and already counted values
+func (d *D) addChild(v *Value) {
 	v.Parent = d.Value
 
 	switch fv := d.Value.V.(type) {
@@ -865,6 +953,63 @@ func (d *D) FieldStructValue(name string) *D {
 	return d.FieldStruct(name, func(d *D) {})
 }
 
+// rePanicLimitError panics again with a recovered limit error so that it aborts
+// the whole decode instead of being treated as a failed attempt
+func rePanicLimitError(r recoverfn.Raw) {
+	if le, ok := r.RecoverV.(LimitError); ok {
+		panic(le)
+	}
+}
+
+// FieldOptional tries to decode a struct using fn. If fn fails with a recoverable
+// decode error the position is restored, no field is added and false is returned.
+// Limit errors are not recovered.
+func (d *D) FieldOptional(name string, fn func(d *D)) bool {
+	startPos := d.Pos()
+	fieldCountStart := d.Options.fieldCountMark()
+	c := &Compound{IsArray: false}
+	cd := d.fieldDecoder(name, d.bitBuf, c)
+	// attach before decoding so that errors and paths include parents
+	d.AddChild(cd.Value)
+	if r, ok := recoverfn.Run(func() { fn(cd) }); !ok {
+		rePanicLimitError(r)
+		if err := cd.Value.Remove(); err != nil {
+			d.Fatalf("FieldOptional: %s", err)
+		}
+		d.Options.fieldCountRollback(fieldCountStart)
+		d.SeekAbs(startPos)
+		return false
+	}
+	return true
+}
+
+// DetectEndian calls fn with big endian and then little endian and sets d.Endian to
+// the first where fn returns true. fn is called at current position with a decoder
+// where added fields are discarded, position is restored after each call and a read
+// error is treated as false. Returns false and leave d.Endian unchanged if none matched.
+// Useful for formats that exist in both byte orders without an explicit marker.
+// Limit errors are not recovered.
+func (d *D) DetectEndian(fn func(d *D) bool) bool {
+	startPos := d.Pos()
+	fieldCountStart := d.Options.fieldCountMark()
+	for _, e := range []Endian{BigEndian, LittleEndian} {
+		cd := d.fieldDecoder("", d.bitBuf, &Compound{IsArray: false})
+		cd.Endian = e
+		var ok bool
+		r, rOk := recoverfn.Run(func() { ok = fn(cd) })
+		if !rOk {
+			rePanicLimitError(r)
+		}
+		d.Options.fieldCountRollback(fieldCountStart)
+		d.SeekAbs(startPos)
+		if rOk && ok {
+			d.Endian = e
+			return true
+		}
+	}
+	return false
+}
+
 func (d *D) FieldStructArrayLoop(name string, structName string, condFn func() bool, fn func(d *D)) *D {
 	return d.FieldArray(name, func(d *D) {
 		for condFn() {
@@ -890,6 +1035,65 @@ func (d *D) FieldArrayLoop(name string, condFn func() bool, fn func(d *D)) *D {
 	})
 }
 
+// FieldArrayLoopAdvance is same as FieldArrayLoop but fails if fn does not advance
+// the position. Use for loops that would spin forever if fn reads nothing,
+// ex: condFn checks for end.
+func (d *D) FieldArrayLoopAdvance(name string, condFn func() bool, fn func(d *D)) *D {
+	return d.FieldArray(name, func(d *D) {
+		for condFn() {
+			start := d.Pos()
+			fn(d)
+			d.assertAdvanced(start, name)
+		}
+	})
+}
+
+// FieldBitFlags reads a nBits unsigned integer in current endian and adds a struct
+// with a boolean field for each bit in flags. flags maps bit index, 0 is least significant
+// bit, to field name. Each field has a one bit range at the position where the bit
+// is in the input. Returns the read integer.
+func (d *D) FieldBitFlags(name string, nBits int, flags map[int]string) uint64 {
+	if d.Endian == LittleEndian && nBits%8 != 0 {
+		d.Fatalf("%s: little endian bit flags must be whole bytes (%d bits)", name, nBits)
+	}
+
+	start := d.Pos()
+	n := d.U(nBits)
+
+	bitIndexes := make([]int, 0, len(flags))
+	for i := range flags {
+		if i < 0 || i >= nBits {
+			d.Fatalf("%s: bit index %d outside %d bits", name, i, nBits)
+		}
+		bitIndexes = append(bitIndexes, i)
+	}
+	// add in input order
+	bitPos := func(i int) int64 {
+		if d.Endian == LittleEndian {
+			return int64((i/8)*8 + 7 - i%8)
+		}
+		return int64(nBits - 1 - i)
+	}
+	sort.Slice(bitIndexes, func(a, b int) bool { return bitPos(bitIndexes[a]) < bitPos(bitIndexes[b]) })
+
+	d.FieldStruct(name, func(d *D) {
+		for _, i := range bitIndexes {
+			v := n&(1<<i) != 0
+			d.FieldRangeFn(flags[i], start+bitPos(i), 1, func() *Value {
+				return &Value{V: &scalar.Bool{Actual: v}}
+			})
+		}
+	})
+
+	return n
+}
+
+func (d *D) assertAdvanced(start int64, name string) {
+	if d.Pos() == start {
+		d.Fatalf("%s: loop did not advance position", name)
+	}
+}
+
 func (d *D) FieldRangeFn(name string, firstBit int64, nBits int64, fn func() *Value) *Value {
 	v := fn()
 	v.Name = name
@@ -928,7 +1132,8 @@ func (d *D) AssertLeastBytesLeft(nBytes int64) {
 	}
 }
 
-// FramedFn decode from current position nBits forward. When done position will be nBits forward.
+// FramedFn decodes using fn from current position limited to nBits, when done position will be nBits forward.
+// Returns number of bits decoded by fn, nBits minus that is what fn did not consume.
 func (d *D) FramedFn(nBits int64, fn func(d *D)) int64 {
 	if nBits < 0 {
 		d.Fatalf("%d nBits < 0", nBits)
@@ -938,6 +1143,48 @@ func (d *D) FramedFn(nBits int64, fn func(d *D)) int64 {
 	return decodeLen
 }
 
+// ChunkFn decodes a chunk payload, d is framed to the payload size
+type ChunkFn func(d *D, id string)
+
+// FieldChunks decodes <fourcc><u32 size><payload> chunks until end into array name.
+// Size is read using d.Endian, little-endian for RIFF and big-endian for IFF.
+// Payloads are padded to 2 byte alignment. Chunks with id in containers, ex LIST or FORM,
+// have a four character type followed by nested chunks. Payloads of other chunks are
+// decoded using fn from fns for the id or as raw data if there is none.
+func (d *D) FieldChunks(name string, containers []string, fns map[string]ChunkFn) {
+	isContainer := func(id string) bool {
+		for _, c := range containers {
+			if c == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	d.FieldArray(name, func(d *D) {
+		for !d.End() {
+			d.FieldStruct("chunk", func(d *D) {
+				id := d.FieldUTF8("id", 4)
+				size := int64(d.FieldU32("size"))
+				d.FramedFn(size*8, func(d *D) {
+					switch {
+					case isContainer(id):
+						d.FieldUTF8("type", 4)
+						d.FieldChunks("chunks", containers, fns)
+					case fns[id] != nil:
+						fns[id](d, id)
+					default:
+						d.FieldRawLen("data", d.BitsLeft())
+					}
+				})
+				if size%2 != 0 && !d.End() {
+					d.FieldRawLen("align", 8)
+				}
+			})
+		}
+	})
+}
+
 // LimitedFn decode from current position nBits forward. When done position will after last bit decoded.
 func (d *D) LimitedFn(nBits int64, fn func(d *D)) int64 {
 	if nBits < 0 {
@@ -968,15 +1215,36 @@ func (d *D) RangeFn(firstBit int64, nBits int64, fn func(d *D)) int64 {
 	return endPos - startPos
 }
 
+// subDecode decodes a sub format inheriting limits from d, limit errors abort the whole decode
+func (d *D) subDecode(br bitio.ReaderAtSeeker, group *Group, opts Options) (*Value, any, error) {
+	opts.ParseOptsFn = d.Options.ParseOptsFn
+	opts.ReadBuf = d.readBuf
+	opts.MaxDepth = d.Options.MaxDepth
+	opts.MaxFields = d.Options.MaxFields
+	opts.MaxFormatDepth = d.Options.MaxFormatDepth
+	opts.depth = d.Options.depth
+	opts.fieldCount = d.Options.fieldCount
+	opts.formatDepth = d.Options.formatDepth
+
+	fieldCountStart := opts.fieldCountMark()
+	dv, v, err := decode(d.Ctx, br, group, opts)
+	if le, ok := asLimitError(err); ok {
+		panic(le)
+	}
+	// callers discard failed decodes, don't count their fields
+	if dv == nil || dv.Errors() != nil {
+		opts.fieldCountRollback(fieldCountStart)
+	}
+	return dv, v, err
+}
+
 func (d *D) Format(group *Group, inArg any) any {
-	dv, v, err := decode(d.Ctx, d.bitBuf, group, Options{
-		Force:       d.Options.Force,
-		FillGaps:    false,
-		IsRoot:      false,
-		Range:       ranges.Range{Start: d.Pos(), Len: d.BitsLeft()},
-		InArg:       inArg,
-		ParseOptsFn: d.Options.ParseOptsFn,
-		ReadBuf:     d.readBuf,
+	dv, v, err := d.subDecode(d.bitBuf, group, Options{
+		Force:    d.Options.Force,
+		FillGaps: false,
+		IsRoot:   false,
+		Range:    ranges.Range{Start: d.Pos(), Len: d.BitsLeft()},
+		InArg:    inArg,
 	})
 	if dv == nil || dv.Errors() != nil {
 		d.IOPanic(err, "Format: decode")
@@ -985,7 +1253,7 @@ func (d *D) Format(group *Group, inArg any) any {
 	switch vv := dv.V.(type) {
 	case *Compound:
 		for _, f := range vv.Children {
-			d.AddChild(f)
+			d.addChild(f)
 		}
 	default:
 		panic("unreachable")
@@ -999,15 +1267,13 @@ func (d *D) Format(group *Group, inArg any) any {
 }
 
 func (d *D) TryFieldFormat(name string, group *Group, inArg any) (*Value, any, error) {
-	dv, v, err := decode(d.Ctx, d.bitBuf, group, Options{
-		Name:        name,
-		Force:       d.Options.Force,
-		FillGaps:    false,
-		IsRoot:      false,
-		Range:       ranges.Range{Start: d.Pos(), Len: d.BitsLeft()},
-		InArg:       inArg,
-		ParseOptsFn: d.Options.ParseOptsFn,
-		ReadBuf:     d.readBuf,
+	dv, v, err := d.subDecode(d.bitBuf, group, Options{
+		Name:     name,
+		Force:    d.Options.Force,
+		FillGaps: false,
+		IsRoot:   false,
+		Range:    ranges.Range{Start: d.Pos(), Len: d.BitsLeft()},
+		InArg:    inArg,
 	})
 	if dv == nil || dv.Errors() != nil {
 		return nil, nil, err
@@ -1038,15 +1304,13 @@ func (d *D) FieldFormatOrRaw(name string, group *Group, inArg any) (*Value, any)
 }
 
 func (d *D) TryFieldFormatLen(name string, nBits int64, group *Group, inArg any) (*Value, any, error) {
-	dv, v, err := decode(d.Ctx, d.bitBuf, group, Options{
-		Name:        name,
-		Force:       d.Options.Force,
-		FillGaps:    true,
-		IsRoot:      false,
-		Range:       ranges.Range{Start: d.Pos(), Len: nBits},
-		InArg:       inArg,
-		ParseOptsFn: d.Options.ParseOptsFn,
-		ReadBuf:     d.readBuf,
+	dv, v, err := d.subDecode(d.bitBuf, group, Options{
+		Name:     name,
+		Force:    d.Options.Force,
+		FillGaps: true,
+		IsRoot:   false,
+		Range:    ranges.Range{Start: d.Pos(), Len: nBits},
+		InArg:    inArg,
 	})
 	if dv == nil || dv.Errors() != nil {
 		return nil, nil, err
@@ -1078,15 +1342,13 @@ func (d *D) FieldFormatOrRawLen(name string, nBits int64, group *Group, inArg an
 
 // TODO: return decooder?
 func (d *D) TryFieldFormatRange(name string, firstBit int64, nBits int64, group *Group, inArg any) (*Value, any, error) {
-	dv, v, err := decode(d.Ctx, d.bitBuf, group, Options{
-		Name:        name,
-		Force:       d.Options.Force,
-		FillGaps:    true,
-		IsRoot:      false,
-		Range:       ranges.Range{Start: firstBit, Len: nBits},
-		InArg:       inArg,
-		ParseOptsFn: d.Options.ParseOptsFn,
-		ReadBuf:     d.readBuf,
+	dv, v, err := d.subDecode(d.bitBuf, group, Options{
+		Name:     name,
+		Force:    d.Options.Force,
+		FillGaps: true,
+		IsRoot:   false,
+		Range:    ranges.Range{Start: firstBit, Len: nBits},
+		InArg:    inArg,
 	})
 	if dv == nil || dv.Errors() != nil {
 		return nil, nil, err
@@ -1107,14 +1369,12 @@ func (d *D) FieldFormatRange(name string, firstBit int64, nBits int64, group *Gr
 }
 
 func (d *D) TryFieldFormatBitBuf(name string, br bitio.ReaderAtSeeker, group *Group, inArg any) (*Value, any, error) {
-	dv, v, err := decode(d.Ctx, br, group, Options{
-		Name:        name,
-		Force:       d.Options.Force,
-		FillGaps:    true,
-		IsRoot:      true,
-		InArg:       inArg,
-		ParseOptsFn: d.Options.ParseOptsFn,
-		ReadBuf:     d.readBuf,
+	dv, v, err := d.subDecode(br, group, Options{
+		Name:     name,
+		Force:    d.Options.Force,
+		FillGaps: true,
+		IsRoot:   true,
+		InArg:    inArg,
 	})
 	if dv == nil || dv.Errors() != nil {
 		return nil, nil, err
@@ -1185,24 +1445,36 @@ func (d *D) FieldStructRootBitBufFn(name string, br bitio.ReaderAtSeeker, fn fun
 }
 
 // TODO: range?
-func (d *D) FieldFormatReaderLen(name string, nBits int64, fn func(r io.Reader) (io.ReadCloser, error), group *Group) (*Value, any) {
+// TryFieldFormatReaderLen reads nBits, transforms them using fn (ex: zlib.NewReader) and
+// decodes the result as a root field using group. Position is after nBits even on error
+// so that the caller can keep decoding, ex: if the compressed data is corrupt.
+func (d *D) TryFieldFormatReaderLen(name string, nBits int64, fn func(r io.Reader) (io.ReadCloser, error), group *Group) (*Value, any, error) {
 	br, err := d.TryBitBufLen(nBits)
 	if err != nil {
-		d.IOPanic(err, "FieldFormatReaderLen: BitBufLen")
+		return nil, nil, err
 	}
 
 	bbBR := bitio.NewIOReader(br)
 	r, err := fn(bbBR)
 	if err != nil {
-		d.IOPanic(err, "FieldFormatReaderLen: fn")
+		return nil, nil, err
 	}
 	rBuf, err := io.ReadAll(r)
 	if err != nil {
-		d.IOPanic(err, "FieldFormatReaderLen: ReadAll")
+		return nil, nil, err
 	}
 	rBR := bitio.NewBitReader(rBuf, -1)
 
-	return d.FieldFormatBitBuf(name, rBR, group, nil)
+	return d.TryFieldFormatBitBuf(name, rBR, group, nil)
+}
+
+func (d *D) FieldFormatReaderLen(name string, nBits int64, fn func(r io.Reader) (io.ReadCloser, error), group *Group) (*Value, any) {
+	dv, v, err := d.TryFieldFormatReaderLen(name, nBits, fn, group)
+	if dv == nil || dv.Errors() != nil {
+		d.IOPanic(err, "FieldFormatReaderLen: TryFieldFormatReaderLen")
+	}
+
+	return dv, v
 }
 
 // TODO: too mant return values
@@ -52,13 +52,31 @@ func (d *D) FieldScalarAnyFn(name string, fn func(d *D) scalar.Any, sms ...scala
 	return v
 }
 
+// FieldValueAny adds a synthetic field with a value that is not read from input
 func (d *D) FieldValueAny(name string, a any, sms ...scalar.AnyMapper) {
-	d.FieldScalarAnyFn(name, func(_ *D) scalar.Any { return scalar.Any{Actual: a} }, sms...)
+	v, err := d.tryFieldScalarAnyValue(name, func(_ *D) (scalar.Any, error) { return scalar.Any{Actual: a}, nil }, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "Any", Pos: d.Pos()})
+	}
+	v.IsSynthetic = true
 }
 
 // TryFieldScalarAnyFn tries to add a field, calls any decode function and returns scalar
 func (d *D) TryFieldScalarAnyFn(name string, fn func(d *D) (scalar.Any, error), sms ...scalar.AnyMapper) (*scalar.Any, error) {
-	v, err := d.TryFieldValue(name, func() (*Value, error) {
+	v, err := d.tryFieldScalarAnyValue(name, fn, sms...)
+	if err != nil {
+		return &scalar.Any{}, err
+	}
+	sr, ok := v.V.(*scalar.Any)
+	if !ok {
+		panic("not a scalar value")
+	}
+	return sr, nil
+}
+
+// tryFieldScalarAnyValue tries to add a field, calls any decode function and returns the added value
+func (d *D) tryFieldScalarAnyValue(name string, fn func(d *D) (scalar.Any, error), sms ...scalar.AnyMapper) (*Value, error) {
+	return d.TryFieldValue(name, func() (*Value, error) {
 		s, err := fn(d)
 		if err != nil {
 			return &Value{V: &s}, err
@@ -71,14 +89,6 @@ func (d *D) TryFieldScalarAnyFn(name string, fn func(d *D) (scalar.Any, error),
 		}
 		return &Value{V: &s}, nil
 	})
-	if err != nil {
-		return &scalar.Any{}, err
-	}
-	sr, ok := v.V.(*scalar.Any)
-	if !ok {
-		panic("not a scalar value")
-	}
-	return sr, nil
 }
 
 // Type BigInt
@@ -123,13 +133,31 @@ func (d *D) FieldScalarBigIntFn(name string, fn func(d *D) scalar.BigInt, sms ..
 	return v
 }
 
+// FieldValueBigInt adds a synthetic field with a value that is not read from input
 func (d *D) FieldValueBigInt(name string, a *big.Int, sms ...scalar.BigIntMapper) {
-	d.FieldScalarBigIntFn(name, func(_ *D) scalar.BigInt { return scalar.BigInt{Actual: a} }, sms...)
+	v, err := d.tryFieldScalarBigIntValue(name, func(_ *D) (scalar.BigInt, error) { return scalar.BigInt{Actual: a}, nil }, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "BigInt", Pos: d.Pos()})
+	}
+	v.IsSynthetic = true
 }
 
 // TryFieldScalarBigIntFn tries to add a field, calls *big.Int decode function and returns scalar
 func (d *D) TryFieldScalarBigIntFn(name string, fn func(d *D) (scalar.BigInt, error), sms ...scalar.BigIntMapper) (*scalar.BigInt, error) {
-	v, err := d.TryFieldValue(name, func() (*Value, error) {
+	v, err := d.tryFieldScalarBigIntValue(name, fn, sms...)
+	if err != nil {
+		return &scalar.BigInt{}, err
+	}
+	sr, ok := v.V.(*scalar.BigInt)
+	if !ok {
+		panic("not a scalar value")
+	}
+	return sr, nil
+}
+
+// tryFieldScalarBigIntValue tries to add a field, calls *big.Int decode function and returns the added value
+func (d *D) tryFieldScalarBigIntValue(name string, fn func(d *D) (scalar.BigInt, error), sms ...scalar.BigIntMapper) (*Value, error) {
+	return d.TryFieldValue(name, func() (*Value, error) {
 		s, err := fn(d)
 		if err != nil {
 			return &Value{V: &s}, err
@@ -142,14 +170,6 @@ func (d *D) TryFieldScalarBigIntFn(name string, fn func(d *D) (scalar.BigInt, er
 		}
 		return &Value{V: &s}, nil
 	})
-	if err != nil {
-		return &scalar.BigInt{}, err
-	}
-	sr, ok := v.V.(*scalar.BigInt)
-	if !ok {
-		panic("not a scalar value")
-	}
-	return sr, nil
 }
 
 // Type BitBuf
@@ -194,13 +214,31 @@ func (d *D) FieldScalarBitBufFn(name string, fn func(d *D) scalar.BitBuf, sms ..
 	return v
 }
 
+// FieldValueBitBuf adds a synthetic field with a value that is not read from input
 func (d *D) FieldValueBitBuf(name string, a bitio.ReaderAtSeeker, sms ...scalar.BitBufMapper) {
-	d.FieldScalarBitBufFn(name, func(_ *D) scalar.BitBuf { return scalar.BitBuf{Actual: a} }, sms...)
+	v, err := d.tryFieldScalarBitBufValue(name, func(_ *D) (scalar.BitBuf, error) { return scalar.BitBuf{Actual: a}, nil }, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "BitBuf", Pos: d.Pos()})
+	}
+	v.IsSynthetic = true
 }
 
 // TryFieldScalarBitBufFn tries to add a field, calls bitio.ReaderAtSeeker decode function and returns scalar
 func (d *D) TryFieldScalarBitBufFn(name string, fn func(d *D) (scalar.BitBuf, error), sms ...scalar.BitBufMapper) (*scalar.BitBuf, error) {
-	v, err := d.TryFieldValue(name, func() (*Value, error) {
+	v, err := d.tryFieldScalarBitBufValue(name, fn, sms...)
+	if err != nil {
+		return &scalar.BitBuf{}, err
+	}
+	sr, ok := v.V.(*scalar.BitBuf)
+	if !ok {
+		panic("not a scalar value")
+	}
+	return sr, nil
+}
+
+// tryFieldScalarBitBufValue tries to add a field, calls bitio.ReaderAtSeeker decode function and returns the added value
+func (d *D) tryFieldScalarBitBufValue(name string, fn func(d *D) (scalar.BitBuf, error), sms ...scalar.BitBufMapper) (*Value, error) {
+	return d.TryFieldValue(name, func() (*Value, error) {
 		s, err := fn(d)
 		if err != nil {
 			return &Value{V: &s}, err
@@ -213,14 +251,6 @@ func (d *D) TryFieldScalarBitBufFn(name string, fn func(d *D) (scalar.BitBuf, er
 		}
 		return &Value{V: &s}, nil
 	})
-	if err != nil {
-		return &scalar.BitBuf{}, err
-	}
-	sr, ok := v.V.(*scalar.BitBuf)
-	if !ok {
-		panic("not a scalar value")
-	}
-	return sr, nil
 }
 
 // Type Bool
@@ -265,13 +295,31 @@ func (d *D) FieldScalarBoolFn(name string, fn func(d *D) scalar.Bool, sms ...sca
 	return v
 }
 
+// FieldValueBool adds a synthetic field with a value that is not read from input
 func (d *D) FieldValueBool(name string, a bool, sms ...scalar.BoolMapper) {
-	d.FieldScalarBoolFn(name, func(_ *D) scalar.Bool { return scalar.Bool{Actual: a} }, sms...)
+	v, err := d.tryFieldScalarBoolValue(name, func(_ *D) (scalar.Bool, error) { return scalar.Bool{Actual: a}, nil }, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "Bool", Pos: d.Pos()})
+	}
+	v.IsSynthetic = true
 }
 
 // TryFieldScalarBoolFn tries to add a field, calls bool decode function and returns scalar
 func (d *D) TryFieldScalarBoolFn(name string, fn func(d *D) (scalar.Bool, error), sms ...scalar.BoolMapper) (*scalar.Bool, error) {
-	v, err := d.TryFieldValue(name, func() (*Value, error) {
+	v, err := d.tryFieldScalarBoolValue(name, fn, sms...)
+	if err != nil {
+		return &scalar.Bool{}, err
+	}
+	sr, ok := v.V.(*scalar.Bool)
+	if !ok {
+		panic("not a scalar value")
+	}
+	return sr, nil
+}
+
+// tryFieldScalarBoolValue tries to add a field, calls bool decode function and returns the added value
+func (d *D) tryFieldScalarBoolValue(name string, fn func(d *D) (scalar.Bool, error), sms ...scalar.BoolMapper) (*Value, error) {
+	return d.TryFieldValue(name, func() (*Value, error) {
 		s, err := fn(d)
 		if err != nil {
 			return &Value{V: &s}, err
@@ -284,14 +332,6 @@ func (d *D) TryFieldScalarBoolFn(name string, fn func(d *D) (scalar.Bool, error)
 		}
 		return &Value{V: &s}, nil
 	})
-	if err != nil {
-		return &scalar.Bool{}, err
-	}
-	sr, ok := v.V.(*scalar.Bool)
-	if !ok {
-		panic("not a scalar value")
-	}
-	return sr, nil
 }
 
 // Type Flt
@@ -336,13 +376,31 @@ func (d *D) FieldScalarFltFn(name string, fn func(d *D) scalar.Flt, sms ...scala
 	return v
 }
 
+// FieldValueFlt adds a synthetic field with a value that is not read from input
 func (d *D) FieldValueFlt(name string, a float64, sms ...scalar.FltMapper) {
-	d.FieldScalarFltFn(name, func(_ *D) scalar.Flt { return scalar.Flt{Actual: a} }, sms...)
+	v, err := d.tryFieldScalarFltValue(name, func(_ *D) (scalar.Flt, error) { return scalar.Flt{Actual: a}, nil }, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "Flt", Pos: d.Pos()})
+	}
+	v.IsSynthetic = true
 }
 
 // TryFieldScalarFltFn tries to add a field, calls float64 decode function and returns scalar
 func (d *D) TryFieldScalarFltFn(name string, fn func(d *D) (scalar.Flt, error), sms ...scalar.FltMapper) (*scalar.Flt, error) {
-	v, err := d.TryFieldValue(name, func() (*Value, error) {
+	v, err := d.tryFieldScalarFltValue(name, fn, sms...)
+	if err != nil {
+		return &scalar.Flt{}, err
+	}
+	sr, ok := v.V.(*scalar.Flt)
+	if !ok {
+		panic("not a scalar value")
+	}
+	return sr, nil
+}
+
+// tryFieldScalarFltValue tries to add a field, calls float64 decode function and returns the added value
+func (d *D) tryFieldScalarFltValue(name string, fn func(d *D) (scalar.Flt, error), sms ...scalar.FltMapper) (*Value, error) {
+	return d.TryFieldValue(name, func() (*Value, error) {
 		s, err := fn(d)
 		if err != nil {
 			return &Value{V: &s}, err
@@ -355,14 +413,6 @@ func (d *D) TryFieldScalarFltFn(name string, fn func(d *D) (scalar.Flt, error),
 		}
 		return &Value{V: &s}, nil
 	})
-	if err != nil {
-		return &scalar.Flt{}, err
-	}
-	sr, ok := v.V.(*scalar.Flt)
-	if !ok {
-		panic("not a scalar value")
-	}
-	return sr, nil
 }
 
 // Type Sint
@@ -407,13 +457,31 @@ func (d *D) FieldScalarSintFn(name string, fn func(d *D) scalar.Sint, sms ...sca
 	return v
 }
 
+// FieldValueSint adds a synthetic field with a value that is not read from input
 func (d *D) FieldValueSint(name string, a int64, sms ...scalar.SintMapper) {
-	d.FieldScalarSintFn(name, func(_ *D) scalar.Sint { return scalar.Sint{Actual: a} }, sms...)
+	v, err := d.tryFieldScalarSintValue(name, func(_ *D) (scalar.Sint, error) { return scalar.Sint{Actual: a}, nil }, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "Sint", Pos: d.Pos()})
+	}
+	v.IsSynthetic = true
 }
 
 // TryFieldScalarSintFn tries to add a field, calls int64 decode function and returns scalar
 func (d *D) TryFieldScalarSintFn(name string, fn func(d *D) (scalar.Sint, error), sms ...scalar.SintMapper) (*scalar.Sint, error) {
-	v, err := d.TryFieldValue(name, func() (*Value, error) {
+	v, err := d.tryFieldScalarSintValue(name, fn, sms...)
+	if err != nil {
+		return &scalar.Sint{}, err
+	}
+	sr, ok := v.V.(*scalar.Sint)
+	if !ok {
+		panic("not a scalar value")
+	}
+	return sr, nil
+}
+
+// tryFieldScalarSintValue tries to add a field, calls int64 decode function and returns the added value
+func (d *D) tryFieldScalarSintValue(name string, fn func(d *D) (scalar.Sint, error), sms ...scalar.SintMapper) (*Value, error) {
+	return d.TryFieldValue(name, func() (*Value, error) {
 		s, err := fn(d)
 		if err != nil {
 			return &Value{V: &s}, err
@@ -426,14 +494,6 @@ func (d *D) TryFieldScalarSintFn(name string, fn func(d *D) (scalar.Sint, error)
 		}
 		return &Value{V: &s}, nil
 	})
-	if err != nil {
-		return &scalar.Sint{}, err
-	}
-	sr, ok := v.V.(*scalar.Sint)
-	if !ok {
-		panic("not a scalar value")
-	}
-	return sr, nil
 }
 
 // Type Str
@@ -478,13 +538,31 @@ func (d *D) FieldScalarStrFn(name string, fn func(d *D) scalar.Str, sms ...scala
 	return v
 }
 
+// FieldValueStr adds a synthetic field with a value that is not read from input
 func (d *D) FieldValueStr(name string, a string, sms ...scalar.StrMapper) {
-	d.FieldScalarStrFn(name, func(_ *D) scalar.Str { return scalar.Str{Actual: a} }, sms...)
+	v, err := d.tryFieldScalarStrValue(name, func(_ *D) (scalar.Str, error) { return scalar.Str{Actual: a}, nil }, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "Str", Pos: d.Pos()})
+	}
+	v.IsSynthetic = true
 }
 
 // TryFieldScalarStrFn tries to add a field, calls string decode function and returns scalar
 func (d *D) TryFieldScalarStrFn(name string, fn func(d *D) (scalar.Str, error), sms ...scalar.StrMapper) (*scalar.Str, error) {
-	v, err := d.TryFieldValue(name, func() (*Value, error) {
+	v, err := d.tryFieldScalarStrValue(name, fn, sms...)
+	if err != nil {
+		return &scalar.Str{}, err
+	}
+	sr, ok := v.V.(*scalar.Str)
+	if !ok {
+		panic("not a scalar value")
+	}
+	return sr, nil
+}
+
+// tryFieldScalarStrValue tries to add a field, calls string decode function and returns the added value
+func (d *D) tryFieldScalarStrValue(name string, fn func(d *D) (scalar.Str, error), sms ...scalar.StrMapper) (*Value, error) {
+	return d.TryFieldValue(name, func() (*Value, error) {
 		s, err := fn(d)
 		if err != nil {
 			return &Value{V: &s}, err
@@ -497,14 +575,6 @@ func (d *D) TryFieldScalarStrFn(name string, fn func(d *D) (scalar.Str, error),
 		}
 		return &Value{V: &s}, nil
 	})
-	if err != nil {
-		return &scalar.Str{}, err
-	}
-	sr, ok := v.V.(*scalar.Str)
-	if !ok {
-		panic("not a scalar value")
-	}
-	return sr, nil
 }
 
 // Type Uint
@@ -549,13 +619,31 @@ func (d *D) FieldScalarUintFn(name string, fn func(d *D) scalar.Uint, sms ...sca
 	return v
 }
 
+// FieldValueUint adds a synthetic field with a value that is not read from input
 func (d *D) FieldValueUint(name string, a uint64, sms ...scalar.UintMapper) {
-	d.FieldScalarUintFn(name, func(_ *D) scalar.Uint { return scalar.Uint{Actual: a} }, sms...)
+	v, err := d.tryFieldScalarUintValue(name, func(_ *D) (scalar.Uint, error) { return scalar.Uint{Actual: a}, nil }, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "Uint", Pos: d.Pos()})
+	}
+	v.IsSynthetic = true
 }
 
 // TryFieldScalarUintFn tries to add a field, calls uint64 decode function and returns scalar
 func (d *D) TryFieldScalarUintFn(name string, fn func(d *D) (scalar.Uint, error), sms ...scalar.UintMapper) (*scalar.Uint, error) {
-	v, err := d.TryFieldValue(name, func() (*Value, error) {
+	v, err := d.tryFieldScalarUintValue(name, fn, sms...)
+	if err != nil {
+		return &scalar.Uint{}, err
+	}
+	sr, ok := v.V.(*scalar.Uint)
+	if !ok {
+		panic("not a scalar value")
+	}
+	return sr, nil
+}
+
+// tryFieldScalarUintValue tries to add a field, calls uint64 decode function and returns the added value
+func (d *D) tryFieldScalarUintValue(name string, fn func(d *D) (scalar.Uint, error), sms ...scalar.UintMapper) (*Value, error) {
+	return d.TryFieldValue(name, func() (*Value, error) {
 		s, err := fn(d)
 		if err != nil {
 			return &Value{V: &s}, err
@@ -568,14 +656,6 @@ func (d *D) TryFieldScalarUintFn(name string, fn func(d *D) (scalar.Uint, error)
 		}
 		return &Value{V: &s}, nil
 	})
-	if err != nil {
-		return &scalar.Uint{}, err
-	}
-	sr, ok := v.V.(*scalar.Uint)
-	if !ok {
-		panic("not a scalar value")
-	}
-	return sr, nil
 }
 
 // Require/Assert/Validate BigInt
@@ -19276,6 +19356,52 @@ func (d *D) FieldULEB128(name string, sms ...scalar.UintMapper) uint64 {
 	return d.FieldScalarULEB128(name, sms...).Actual
 }
 
+// Reader UVLQ
+
+// TryUVLQ tries to read unsigned variable-length quantity
+func (d *D) TryUVLQ() (uint64, error) { return d.tryUVLQ() }
+
+// UVLQ reads unsigned variable-length quantity
+func (d *D) UVLQ() uint64 {
+	v, err := d.tryUVLQ()
+	if err != nil {
+		panic(IOError{Err: err, Op: "UVLQ", Pos: d.Pos()})
+	}
+	return v
+}
+
+// TryFieldScalarUVLQ tries to add a field and read unsigned variable-length quantity
+func (d *D) TryFieldScalarUVLQ(name string, sms ...scalar.UintMapper) (*scalar.Uint, error) {
+	s, err := d.TryFieldScalarUintFn(name, func(d *D) (scalar.Uint, error) {
+		v, err := d.tryUVLQ()
+		return scalar.Uint{Actual: v}, err
+	}, sms...)
+	if err != nil {
+		return nil, err
+	}
+	return s, err
+}
+
+// FieldScalarUVLQ adds a field and reads unsigned variable-length quantity
+func (d *D) FieldScalarUVLQ(name string, sms ...scalar.UintMapper) *scalar.Uint {
+	s, err := d.TryFieldScalarUVLQ(name, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "UVLQ", Pos: d.Pos()})
+	}
+	return s
+}
+
+// TryFieldUVLQ tries to add a field and read unsigned variable-length quantity
+func (d *D) TryFieldUVLQ(name string, sms ...scalar.UintMapper) (uint64, error) {
+	s, err := d.TryFieldScalarUVLQ(name, sms...)
+	return s.Actual, err
+}
+
+// FieldUVLQ adds a field and reads unsigned variable-length quantity
+func (d *D) FieldUVLQ(name string, sms ...scalar.UintMapper) uint64 {
+	return d.FieldScalarUVLQ(name, sms...).Actual
+}
+
 // Reader SLEB128
 
 // TrySLEB128 tries to read signed LEB128 integer
@@ -19370,10 +19496,10 @@ func (d *D) FieldUTF8(name string, nBytes int, sms ...scalar.StrMapper) string {
 
 // Reader UTF16
 
-// TryUTF16 tries to read nBytes bytes UTF16 string, default big-endian and accepts BOM
+// TryUTF16 tries to read nBytes bytes UTF16 string, default little-endian and accepts BOM
 func (d *D) TryUTF16(nBytes int) (string, error) { return d.tryText(nBytes, UTF16BOM) }
 
-// UTF16 reads nBytes bytes UTF16 string, default big-endian and accepts BOM
+// UTF16 reads nBytes bytes UTF16 string, default little-endian and accepts BOM
 func (d *D) UTF16(nBytes int) string {
 	v, err := d.tryText(nBytes, UTF16BOM)
 	if err != nil {
@@ -19382,7 +19508,7 @@ func (d *D) UTF16(nBytes int) string {
 	return v
 }
 
-// TryFieldScalarUTF16 tries to add a field and read nBytes bytes UTF16 string, default big-endian and accepts BOM
+// TryFieldScalarUTF16 tries to add a field and read nBytes bytes UTF16 string, default little-endian and accepts BOM
 func (d *D) TryFieldScalarUTF16(name string, nBytes int, sms ...scalar.StrMapper) (*scalar.Str, error) {
 	s, err := d.TryFieldScalarStrFn(name, func(d *D) (scalar.Str, error) {
 		v, err := d.tryText(nBytes, UTF16BOM)
@@ -19394,7 +19520,7 @@ func (d *D) TryFieldScalarUTF16(name string, nBytes int, sms ...scalar.StrMapper
 	return s, err
 }
 
-// FieldScalarUTF16 adds a field and reads nBytes bytes UTF16 string, default big-endian and accepts BOM
+// FieldScalarUTF16 adds a field and reads nBytes bytes UTF16 string, default little-endian and accepts BOM
 func (d *D) FieldScalarUTF16(name string, nBytes int, sms ...scalar.StrMapper) *scalar.Str {
 	s, err := d.TryFieldScalarUTF16(name, nBytes, sms...)
 	if err != nil {
@@ -19403,13 +19529,13 @@ func (d *D) FieldScalarUTF16(name string, nBytes int, sms ...scalar.StrMapper) *
 	return s
 }
 
-// TryFieldUTF16 tries to add a field and read nBytes bytes UTF16 string, default big-endian and accepts BOM
+// TryFieldUTF16 tries to add a field and read nBytes bytes UTF16 string, default little-endian and accepts BOM
 func (d *D) TryFieldUTF16(name string, nBytes int, sms ...scalar.StrMapper) (string, error) {
 	s, err := d.TryFieldScalarUTF16(name, nBytes, sms...)
 	return s.Actual, err
 }
 
-// FieldUTF16 adds a field and reads nBytes bytes UTF16 string, default big-endian and accepts BOM
+// FieldUTF16 adds a field and reads nBytes bytes UTF16 string, default little-endian and accepts BOM
 func (d *D) FieldUTF16(name string, nBytes int, sms ...scalar.StrMapper) string {
 	return d.FieldScalarUTF16(name, nBytes, sms...).Actual
 }
@@ -19648,10 +19774,10 @@ func (d *D) FieldUTF8Null(name string, sms ...scalar.StrMapper) string {
 
 // Reader UTF16Null
 
-// TryUTF16Null tries to read null terminated UTF16 string, default big-endian and accepts BOM
+// TryUTF16Null tries to read null terminated UTF16 string, default little-endian and accepts BOM
 func (d *D) TryUTF16Null() (string, error) { return d.tryTextNull(2, UTF16BOM) }
 
-// UTF16Null reads null terminated UTF16 string, default big-endian and accepts BOM
+// UTF16Null reads null terminated UTF16 string, default little-endian and accepts BOM
 func (d *D) UTF16Null() string {
 	v, err := d.tryTextNull(2, UTF16BOM)
 	if err != nil {
@@ -19660,7 +19786,7 @@ func (d *D) UTF16Null() string {
 	return v
 }
 
-// TryFieldScalarUTF16Null tries to add a field and read null terminated UTF16 string, default big-endian and accepts BOM
+// TryFieldScalarUTF16Null tries to add a field and read null terminated UTF16 string, default little-endian and accepts BOM
 func (d *D) TryFieldScalarUTF16Null(name string, sms ...scalar.StrMapper) (*scalar.Str, error) {
 	s, err := d.TryFieldScalarStrFn(name, func(d *D) (scalar.Str, error) {
 		v, err := d.tryTextNull(2, UTF16BOM)
@@ -19672,7 +19798,7 @@ func (d *D) TryFieldScalarUTF16Null(name string, sms ...scalar.StrMapper) (*scal
 	return s, err
 }
 
-// FieldScalarUTF16Null adds a field and reads null terminated UTF16 string, default big-endian and accepts BOM
+// FieldScalarUTF16Null adds a field and reads null terminated UTF16 string, default little-endian and accepts BOM
 func (d *D) FieldScalarUTF16Null(name string, sms ...scalar.StrMapper) *scalar.Str {
 	s, err := d.TryFieldScalarUTF16Null(name, sms...)
 	if err != nil {
@@ -19681,13 +19807,13 @@ func (d *D) FieldScalarUTF16Null(name string, sms ...scalar.StrMapper) *scalar.S
 	return s
 }
 
-// TryFieldUTF16Null tries to add a field and read null terminated UTF16 string, default big-endian and accepts BOM
+// TryFieldUTF16Null tries to add a field and read null terminated UTF16 string, default little-endian and accepts BOM
 func (d *D) TryFieldUTF16Null(name string, sms ...scalar.StrMapper) (string, error) {
 	s, err := d.TryFieldScalarUTF16Null(name, sms...)
 	return s.Actual, err
 }
 
-// FieldUTF16Null adds a field and reads null terminated UTF16 string, default big-endian and accepts BOM
+// FieldUTF16Null adds a field and reads null terminated UTF16 string, default little-endian and accepts BOM
 func (d *D) FieldUTF16Null(name string, sms ...scalar.StrMapper) string {
 	return d.FieldScalarUTF16Null(name, sms...).Actual
 }
@@ -19832,6 +19958,54 @@ func (d *D) FieldUTF8NullFixedLen(name string, fixedBytes int, sms ...scalar.Str
 	return d.FieldScalarUTF8NullFixedLen(name, fixedBytes, sms...).Actual
 }
 
+// Reader UTF8NullMax
+
+// TryUTF8NullMax tries to read null terminated UTF8 string of at most maxBytes bytes (-1 no limit) including terminator, stops at end of buffer if no terminator is found
+func (d *D) TryUTF8NullMax(maxBytes int) (string, error) {
+	return d.tryTextNullMax(1, maxBytes, UTF8BOM)
+}
+
+// UTF8NullMax reads null terminated UTF8 string of at most maxBytes bytes (-1 no limit) including terminator, stops at end of buffer if no terminator is found
+func (d *D) UTF8NullMax(maxBytes int) string {
+	v, err := d.tryTextNullMax(1, maxBytes, UTF8BOM)
+	if err != nil {
+		panic(IOError{Err: err, Op: "UTF8NullMax", Pos: d.Pos()})
+	}
+	return v
+}
+
+// TryFieldScalarUTF8NullMax tries to add a field and read null terminated UTF8 string of at most maxBytes bytes (-1 no limit) including terminator, stops at end of buffer if no terminator is found
+func (d *D) TryFieldScalarUTF8NullMax(name string, maxBytes int, sms ...scalar.StrMapper) (*scalar.Str, error) {
+	s, err := d.TryFieldScalarStrFn(name, func(d *D) (scalar.Str, error) {
+		v, err := d.tryTextNullMax(1, maxBytes, UTF8BOM)
+		return scalar.Str{Actual: v}, err
+	}, sms...)
+	if err != nil {
+		return nil, err
+	}
+	return s, err
+}
+
+// FieldScalarUTF8NullMax adds a field and reads null terminated UTF8 string of at most maxBytes bytes (-1 no limit) including terminator, stops at end of buffer if no terminator is found
+func (d *D) FieldScalarUTF8NullMax(name string, maxBytes int, sms ...scalar.StrMapper) *scalar.Str {
+	s, err := d.TryFieldScalarUTF8NullMax(name, maxBytes, sms...)
+	if err != nil {
+		panic(IOError{Err: err, Name: name, Op: "UTF8NullMax", Pos: d.Pos()})
+	}
+	return s
+}
+
+// TryFieldUTF8NullMax tries to add a field and read null terminated UTF8 string of at most maxBytes bytes (-1 no limit) including terminator, stops at end of buffer if no terminator is found
+func (d *D) TryFieldUTF8NullMax(name string, maxBytes int, sms ...scalar.StrMapper) (string, error) {
+	s, err := d.TryFieldScalarUTF8NullMax(name, maxBytes, sms...)
+	return s.Actual, err
+}
+
+// FieldUTF8NullMax adds a field and reads null terminated UTF8 string of at most maxBytes bytes (-1 no limit) including terminator, stops at end of buffer if no terminator is found
+func (d *D) FieldUTF8NullMax(name string, maxBytes int, sms ...scalar.StrMapper) string {
+	return d.FieldScalarUTF8NullMax(name, maxBytes, sms...).Actual
+}
+
 // Reader Str
 
 // TryStr tries to read nBytes bytes using encoding e
@@ -0,0 +1,559 @@
+package decode_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/wader/fq/pkg/bitio"
+	"github.com/wader/fq/pkg/decode"
+	"github.com/wader/fq/pkg/scalar"
+)
+
+// decodeBytes runs fn as a format decoder on bs, returns decode error if any
+func decodeBytes(t *testing.T, bs []byte, fn func(d *decode.D)) error {
+	t.Helper()
+	_, _, err := decode.Decode(context.Background(), bitio.NewBitReader(bs, -1), decode.FormatFn(func(d *decode.D) any {
+		fn(d)
+		return nil
+	}), decode.Options{})
+	return err
+}
+
+func TestFieldOptional(t *testing.T) {
+	if err := decodeBytes(t, []byte("RIFFabcd"), func(d *decode.D) {
+		if d.FieldOptional("form", func(d *decode.D) {
+			d.FieldUTF8("id", 4, d.StrAssert("FORM"))
+		}) {
+			t.Error("expected FORM to fail")
+		}
+		if d.Pos() != 0 {
+			t.Errorf("expected position to be restored, got %d", d.Pos())
+		}
+		if d.FieldGet("form") != nil {
+			t.Error("expected no form field")
+		}
+
+		if !d.FieldOptional("riff", func(d *decode.D) {
+			d.FieldUTF8("id", 4, d.StrAssert("RIFF"))
+		}) {
+			t.Error("expected RIFF to succeed")
+		}
+		if d.Pos() != 32 {
+			t.Errorf("expected position 32, got %d", d.Pos())
+		}
+		if d.FieldGet("riff") == nil {
+			t.Error("expected riff field")
+		}
+	}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFieldOptionalParent(t *testing.T) {
+	if err := decodeBytes(t, []byte("abcd"), func(d *decode.D) {
+		d.FieldStruct("outer", func(d *decode.D) {
+			var seekErr error
+			if d.FieldOptional("opt", func(d *decode.D) {
+				_, seekErr = d.TrySeekAbs(1000)
+				d.Fatalf("fail")
+			}) {
+				t.Error("expected opt to fail")
+			}
+			expected := "seek to bit 1000 in .outer.opt outside buffer of 32 bits"
+			if seekErr == nil || seekErr.Error() != expected {
+				t.Errorf("expected error %q, got %v", expected, seekErr)
+			}
+			// failed attempt is detached so the name can be reused
+			if !d.FieldOptional("opt", func(d *decode.D) { d.FieldU8("a") }) {
+				t.Error("expected second opt to succeed")
+			}
+		})
+	}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFieldCRC(t *testing.T) {
+	testCases := []struct {
+		name     string
+		crc      []byte
+		fn       func(d *decode.D, firstBit int64, nBits int64) uint64
+		expected string
+	}{
+		{name: "crc32", crc: []byte{0xcb, 0xf4, 0x39, 0x26}, fn: func(d *decode.D, firstBit int64, nBits int64) uint64 {
+			return d.FieldCRC32IEEE("crc", firstBit, nBits)
+		}, expected: "valid"},
+		{name: "crc32 invalid", crc: []byte{0xcb, 0xf4, 0x39, 0x27}, fn: func(d *decode.D, firstBit int64, nBits int64) uint64 {
+			return d.FieldCRC32IEEE("crc", firstBit, nBits)
+		}, expected: "invalid"},
+		{name: "crc16", crc: []byte{0x29, 0xb1}, fn: func(d *decode.D, firstBit int64, nBits int64) uint64 {
+			return d.FieldCRC16CCITT("crc", firstBit, nBits)
+		}, expected: "valid"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			bs := append([]byte("123456789"), tc.crc...)
+			if err := decodeBytes(t, bs, func(d *decode.D) {
+				d.FieldRawLen("data", 9*8)
+				tc.fn(d, 0, 9*8)
+				v := d.FieldGet("crc")
+				if v == nil {
+					t.Fatal("expected crc field")
+				}
+				s, ok := v.V.(*scalar.Uint)
+				if !ok {
+					t.Fatalf("expected uint scalar, got %T", v.V)
+				}
+				if s.Description != tc.expected {
+					t.Errorf("expected %q, got %q", tc.expected, s.Description)
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestFieldChunks(t *testing.T) {
+	bs := []byte{}
+	bs = append(bs, "RIFF\x1a\x00\x00\x00WAVE"...)
+	bs = append(bs, "fmt \x03\x00\x00\x00abc\x00"...)
+	bs = append(bs, "data\x02\x00\x00\x00de"...)
+	bs = append(bs, "JUNK\x01\x00\x00\x00f"...)
+
+	var fmtData string
+	if err := decodeBytes(t, bs, func(d *decode.D) {
+		d.Endian = decode.LittleEndian
+		d.FieldChunks("chunks", []string{"RIFF"}, map[string]decode.ChunkFn{
+			"fmt ": func(d *decode.D, id string) {
+				fmtData = d.FieldUTF8("fmt", int(d.BitsLeft()/8))
+			},
+		})
+
+		riff := d.FieldGet("chunks").V.(*decode.Compound).Children[0]
+		nested := riff.V.(*decode.Compound).Children[3].V.(*decode.Compound).Children
+		if len(nested) != 2 {
+			t.Fatalf("expected 2 nested chunks, got %d", len(nested))
+		}
+		var names []string
+		for _, c := range nested[0].V.(*decode.Compound).Children {
+			names = append(names, c.Name)
+		}
+		if strings.Join(names, ",") != "id,size,fmt,align" {
+			t.Errorf("unexpected fmt chunk fields %v", names)
+		}
+		if d.BitsLeft() != 0 {
+			t.Errorf("expected all bits consumed, %d left", d.BitsLeft())
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if fmtData != "abc" {
+		t.Errorf("expected fmt chunk fn to decode abc, got %q", fmtData)
+	}
+}
+
+func TestMaxDepthAndFields(t *testing.T) {
+	var nest func(d *decode.D)
+	nest = func(d *decode.D) {
+		d.FieldU8("a")
+		if !d.End() {
+			d.FieldStruct("s", nest)
+		}
+	}
+	decodeLimited := func(opts decode.Options) (*decode.Value, error) {
+		dv, _, err := decode.Decode(context.Background(), bitio.NewBitReader(make([]byte, 100), -1), decode.FormatFn(func(d *decode.D) any {
+			nest(d)
+			return nil
+		}), opts)
+		return dv, err
+	}
+
+	testCases := []struct {
+		opts     decode.Options
+		expected string
+	}{
+		{decode.Options{MaxDepth: 10}, `error at position 0xb: "s" exceeds max depth 10`},
+		{decode.Options{MaxFields: 10}, `error at position 0x6: "a" exceeds max fields 10`},
+	}
+	for _, tc := range testCases {
+		dv, err := decodeLimited(tc.opts)
+		if err == nil || err.Error() != tc.expected {
+			t.Errorf("expected error %q, got %v", tc.expected, err)
+		}
+		if dv == nil || dv.V.(*decode.Compound).Children == nil {
+			t.Errorf("expected partial value")
+		}
+	}
+
+	if _, err := decodeLimited(decode.Options{}); err != nil {
+		t.Errorf("expected no error without limits, got %v", err)
+	}
+}
+
+func TestMaxFieldsDiscardedTrials(t *testing.T) {
+	const maxFields = 6
+	failingProbe := func(d *decode.D) any {
+		d.FieldU8("p0")
+		d.FieldU8("p1")
+		d.FieldU8("p2")
+		d.Fatalf("not this format")
+		return nil
+	}
+	testCases := []struct {
+		name string
+		fn   func(d *decode.D)
+		kept int
+	}{
+		{"FieldOptional", func(d *decode.D) {
+			d.FieldOptional("s", func(d *decode.D) { failingProbe(d) })
+		}, 0},
+		{"DetectEndian", func(d *decode.D) {
+			d.DetectEndian(func(d *decode.D) bool { failingProbe(d); return true })
+		}, 0},
+		{"TryFieldFormat", func(d *decode.D) {
+			d.TryFieldFormat("f", decode.FormatFn(failingProbe), nil)
+		}, 0},
+		{"FieldFormatProbe", func(d *decode.D) {
+			d.FieldFormat("f", &decode.Group{Formats: []*decode.Format{
+				{DecodeFn: failingProbe},
+				{DecodeFn: func(d *decode.D) any { d.FieldU8("x"); return nil }},
+			}}, nil)
+		}, 2},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := decode.Decode(context.Background(), bitio.NewBitReader(make([]byte, 100), -1), decode.FormatFn(func(d *decode.D) any {
+				tc.fn(d)
+				d.SeekAbs(0)
+				// fill up to max fields, discarded fields should not count
+				for i := tc.kept; i < maxFields; i++ {
+					d.FieldU8(fmt.Sprintf("a%d", i))
+				}
+				return nil
+			}), decode.Options{MaxFields: maxFields})
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestLimitErrorNotRecovered(t *testing.T) {
+	manyFields := func(d *decode.D) {
+		for i := 0; i < 10; i++ {
+			d.FieldU8(fmt.Sprintf("a%d", i))
+		}
+	}
+	testCases := []struct {
+		name string
+		fn   func(d *decode.D)
+	}{
+		{"FieldOptional", func(d *decode.D) {
+			d.FieldOptional("s", manyFields)
+		}},
+		{"DetectEndian", func(d *decode.D) {
+			d.DetectEndian(func(d *decode.D) bool { manyFields(d); return true })
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := decode.Decode(context.Background(), bitio.NewBitReader(make([]byte, 100), -1), decode.FormatFn(func(d *decode.D) any {
+				tc.fn(d)
+				return nil
+			}), decode.Options{MaxFields: 5})
+			expected := "exceeds max fields 5"
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestRawSymFn(t *testing.T) {
+	if err := decodeBytes(t, []byte{0x00, 0x01, 0x80, 0x00}, func(d *decode.D) {
+		d.FieldRawLen("fixed", 32, scalar.RawSymFn(func(b []byte) any {
+			return float64(binary.BigEndian.Uint32(b)) / 0x10000
+		}))
+		v := d.FieldGet("fixed")
+		s := v.V.(*scalar.BitBuf)
+		if s.Sym != 1.5 {
+			t.Errorf("expected sym 1.5, got %v", s.Sym)
+		}
+		if v.Range.Start != 0 || v.Range.Len != 32 {
+			t.Errorf("expected range 0+32, got %d+%d", v.Range.Start, v.Range.Len)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFixedPoint(t *testing.T) {
+	if err := decodeBytes(t, []byte{
+		0x00, 0x01, 0x80, 0x00, // 16.16 1.5
+		0x00, 0x00, 0x00, 0x01, // 16.16 lsb
+		0xff, 0xff, 0xff, 0xff, // 16.16 max
+		0xff, 0x80, // signed 8.8 -0.5
+		0xff, 0xff, // signed 8.8 -lsb
+		0x7f, 0xff, // signed 8.8 max
+	}, func(d *decode.D) {
+		uintCases := []float64{1.5, 1.0 / 65536, 65535 + 65535.0/65536}
+		for i, expected := range uintCases {
+			s := d.FieldScalarU32(fmt.Sprintf("u%d", i), scalar.UintFixedPoint(16))
+			if s.Sym != expected {
+				t.Errorf("expected %v, got %v (actual %x)", expected, s.Sym, s.Actual)
+			}
+		}
+		sintCases := []float64{-0.5, -1.0 / 256, 127 + 255.0/256}
+		for i, expected := range sintCases {
+			s := d.FieldScalarS16(fmt.Sprintf("s%d", i), scalar.SintFixedPoint(8))
+			if s.Sym != expected {
+				t.Errorf("expected %v, got %v (actual %d)", expected, s.Sym, s.Actual)
+			}
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFieldArrayLoopAdvance(t *testing.T) {
+	err := decodeBytes(t, []byte{0x01, 0x02}, func(d *decode.D) {
+		d.FieldArrayLoopAdvance("events", func() bool { return d.BitsLeft() > 0 }, func(d *decode.D) {
+			d.FieldU8("type")
+		})
+	})
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestFieldArrayLoopAdvanceNoAdvance(t *testing.T) {
+	err := decodeBytes(t, []byte{0x01, 0x00, 0x02}, func(d *decode.D) {
+		d.FieldArrayLoopAdvance("events", func() bool { return d.BitsLeft() > 0 }, func(d *decode.D) {
+			// zero length event, would spin forever without the guard
+			if d.PeekUintBits(8) == 0 {
+				return
+			}
+			d.FieldU8("type")
+		})
+	})
+	expected := "error at position 0x1: events: loop did not advance position"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestFramedFn(t *testing.T) {
+	testCases := []struct {
+		name          string
+		readBytes     int
+		expectedUsed  int64
+		expectedTrail int64
+	}{
+		{"exact fit", 4, 32, 0},
+		{"under consumed", 2, 16, 16},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := decodeBytes(t, []byte{1, 2, 3, 4, 5}, func(d *decode.D) {
+				const frameLen = 32
+				used := d.FramedFn(frameLen, func(d *decode.D) {
+					d.FieldRawLen("data", int64(tc.readBytes)*8)
+				})
+				if used != tc.expectedUsed || frameLen-used != tc.expectedTrail {
+					t.Errorf("expected used %d trailing %d, got %d %d", tc.expectedUsed, tc.expectedTrail, used, frameLen-used)
+				}
+				if d.Pos() != frameLen {
+					t.Errorf("expected position %d, got %d", frameLen, d.Pos())
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSeekOutsideBuffer(t *testing.T) {
+	err := decodeBytes(t, []byte{0x01, 0x02}, func(d *decode.D) {
+		d.FieldArray("chunks", func(d *decode.D) {
+			d.FieldStruct("chunk", func(d *decode.D) {
+				size := d.FieldU8("size")
+				d.SeekRel(int64(size) * 8 * 100)
+			})
+		})
+	})
+	expected := "SeekRel: failed at position 1 (read size 0 seek pos 0): seek to bit 808 in .chunks[0] outside buffer of 16 bits"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+
+	if err := decodeBytes(t, []byte{0x01, 0x02}, func(d *decode.D) {
+		if _, err := d.TrySeekAbs(-1); err == nil {
+			t.Error("expected error seeking to negative position")
+		}
+		d.SeekAbs(16)
+		if !d.End() {
+			t.Error("expected seek to end to be allowed")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTryFieldFormatReaderLen(t *testing.T) {
+	zb := &bytes.Buffer{}
+	zw := zlib.NewWriter(zb)
+	_, _ = zw.Write([]byte("abc"))
+	zw.Close()
+	compressed := zb.Bytes()
+	corrupt := append([]byte{}, compressed...)
+	corrupt[len(corrupt)-1] ^= 0xff
+
+	textGroup := decode.FormatFn(func(d *decode.D) any {
+		d.FieldUTF8("text", int(d.BitsLeft()/8))
+		return nil
+	})
+
+	if err := decodeBytes(t, compressed, func(d *decode.D) {
+		dv, _, err := d.TryFieldFormatReaderLen("uncompressed", d.BitsLeft(), zlib.NewReader, textGroup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dv == nil || d.FieldGet("uncompressed") == nil {
+			t.Error("expected uncompressed field")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := decodeBytes(t, corrupt, func(d *decode.D) {
+		d.FieldRawLen("compressed", d.BitsLeft())
+		d.SeekAbs(0)
+		dv, _, err := d.TryFieldFormatReaderLen("uncompressed", d.BitsLeft(), zlib.NewReader, textGroup)
+		if err == nil {
+			t.Error("expected error for corrupt data")
+		}
+		if dv != nil || d.FieldGet("uncompressed") != nil {
+			t.Error("expected no uncompressed field")
+		}
+		if d.FieldGet("compressed") == nil {
+			t.Error("expected compressed field")
+		}
+		if !d.End() {
+			t.Errorf("expected position at end, got %d", d.Pos())
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFieldValueIsSynthetic(t *testing.T) {
+	if err := decodeBytes(t, []byte{0x78}, func(d *decode.D) {
+		n := d.FieldU8("n")
+		d.FieldValueUint("double", n*2)
+		d.FieldArray("a", func(d *decode.D) {
+			d.FieldValueStr("s", "x")
+		})
+
+		if d.FieldGet("n").IsSynthetic {
+			t.Error("expected read field to not be synthetic")
+		}
+		if !d.FieldGet("double").IsSynthetic {
+			t.Error("expected value field to be synthetic")
+		}
+		if !d.FieldGet("a").V.(*decode.Compound).Children[0].IsSynthetic {
+			t.Error("expected value field in array to be synthetic")
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFieldBitFlags(t *testing.T) {
+	type flag struct {
+		pos int64
+		v   bool
+	}
+	flags := map[int]string{0: "a", 7: "b", 8: "c", 15: "d"}
+	testCases := []struct {
+		endian   decode.Endian
+		expected uint64
+		flags    map[string]flag
+	}{
+		{decode.BigEndian, 0x8001, map[string]flag{"a": {15, true}, "b": {8, false}, "c": {7, false}, "d": {0, true}}},
+		{decode.LittleEndian, 0x0180, map[string]flag{"a": {7, false}, "b": {0, true}, "c": {15, true}, "d": {8, false}}},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("endian %d", tc.endian), func(t *testing.T) {
+			if err := decodeBytes(t, []byte{0x80, 0x01}, func(d *decode.D) {
+				d.Endian = tc.endian
+				n := d.FieldBitFlags("flags", 16, flags)
+				if n != tc.expected {
+					t.Errorf("expected %x, got %x", tc.expected, n)
+				}
+				if d.Pos() != 16 {
+					t.Errorf("expected position 16, got %d", d.Pos())
+				}
+				for name, f := range tc.flags {
+					v := d.FieldGet("flags").V.(*decode.Compound).ByName[name]
+					if v.Range.Start != f.pos || v.Range.Len != 1 {
+						t.Errorf("%s: expected range %d:1, got %d:%d", name, f.pos, v.Range.Start, v.Range.Len)
+					}
+					if actual := v.V.(*scalar.Bool).Actual; actual != f.v {
+						t.Errorf("%s: expected %t, got %t", name, f.v, actual)
+					}
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDetectEndian(t *testing.T) {
+	tiffMagic := func(d *decode.D) bool {
+		d.FieldUTF8("order", 2)
+		return d.FieldU16("magic") == 42
+	}
+	testCases := []struct {
+		bs       []byte
+		ok       bool
+		expected decode.Endian
+	}{
+		{[]byte("MM\x00*"), true, decode.BigEndian},
+		{[]byte("II*\x00"), true, decode.LittleEndian},
+		{[]byte("XX\x00\x00"), false, decode.BigEndian},
+		{[]byte("II"), false, decode.BigEndian},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%q", tc.bs), func(t *testing.T) {
+			if err := decodeBytes(t, tc.bs, func(d *decode.D) {
+				ok := d.DetectEndian(tiffMagic)
+				if ok != tc.ok {
+					t.Errorf("expected %t, got %t", tc.ok, ok)
+				}
+				if d.Endian != tc.expected {
+					t.Errorf("expected endian %d, got %d", tc.expected, d.Endian)
+				}
+				if d.Pos() != 0 {
+					t.Errorf("expected position to be restored, got %d", d.Pos())
+				}
+				if len(d.Value.V.(*decode.Compound).Children) != 0 {
+					t.Error("expected no fields to be added")
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -88,3 +88,33 @@ func (e DecoderError) Error() string {
 }
 
 func (DecoderError) IsRecoverableError() bool { return true }
+
+// LimitError is used when decoding exceeds Options.MaxDepth or Options.MaxFields.
+// Is passed thru nested format decodes so that decoders don't treat it as a failed probe.
+type LimitError struct {
+	Reason string
+	Pos    int64
+}
+
+func (e LimitError) Error() string {
+	return fmt.Sprintf("error at position %s: %s", mathex.Bits(e.Pos).StringByteBits(16), e.Reason)
+}
+
+func (LimitError) IsRecoverableError() bool { return true }
+
+// asLimitError finds a LimitError in err or in any of its format errors
+func asLimitError(err error) (LimitError, bool) {
+	switch err := err.(type) {
+	case LimitError:
+		return err, true
+	case FormatError:
+		return asLimitError(err.Err)
+	case FormatsError:
+		for _, fe := range err.Errs {
+			if le, ok := asLimitError(fe); ok {
+				return le, true
+			}
+		}
+	}
+	return LimitError{}, false
+}
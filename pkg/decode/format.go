@@ -4,6 +4,7 @@ type Group struct {
 	Name         string
 	Formats      []*Format
 	DefaultInArg any
+	IsProbe      bool // formats in group are probeable and count towards MaxFormatDepth
 }
 
 type Dependency struct {
@@ -32,3 +33,13 @@ func FormatFn(fn func(d *D) any) *Group {
 		},
 	}
 }
+
+// isProbeFormat returns true if format is in a probe group, usually a file format
+func isProbeFormat(f *Format) bool {
+	for _, g := range f.Groups {
+		if g.IsProbe {
+			return true
+		}
+	}
+	return false
+}
@@ -205,6 +205,39 @@ func (d *D) tryTextNull(charBytes int, e encoding.Encoding) (string, error) {
 	return e.NewDecoder().String(string(bs[0 : n-charBytes]))
 }
 
+// tryTextNullMax reads until a null character, end of buffer or maxBytes bytes
+// whichever comes first. A found terminator is included in the range but not in
+// the string.
+func (d *D) tryTextNullMax(charBytes int, maxBytes int, e encoding.Encoding) (string, error) {
+	if charBytes < 1 {
+		return "", fmt.Errorf("tryTextNullMax charBytes must be >= 1 (%d)", charBytes)
+	}
+
+	n := int(d.BitsLeft() / 8)
+	if maxBytes >= 0 && maxBytes < n {
+		n = maxBytes
+	}
+	n -= n % charBytes
+
+	strBytes := n
+	if n > 0 {
+		peekBits, _, err := d.TryPeekFind(charBytes*8, int64(charBytes)*8, int64(n)*8, func(v uint64) bool { return v == 0 })
+		if err != nil {
+			return "", err
+		}
+		if peekBits != -1 {
+			strBytes = int(peekBits) / 8
+			n = strBytes + charBytes
+		}
+	}
+	bs, err := d.TryBytesLen(n)
+	if err != nil {
+		return "", err
+	}
+
+	return e.NewDecoder().String(string(bs[0:strBytes]))
+}
+
 func (d *D) tryTextNullLen(fixedBytes int, e encoding.Encoding) (string, error) {
 	if fixedBytes < 0 {
 		return "", fmt.Errorf("tryTextNullLen fixedBytes must be >= 0 (%d)", fixedBytes)
@@ -261,7 +294,10 @@ func (d *D) tryULEB128() (uint64, error) {
 	var shift uint
 
 	for {
-		b := d.U8()
+		b, err := d.TryUintBits(8)
+		if err != nil {
+			return 0, err
+		}
 		if shift >= 63 && b != 0 {
 			return 0, fmt.Errorf("overflow when reading unsigned leb128, shift %d >= 63", shift)
 		}
@@ -274,6 +310,33 @@ func (d *D) tryULEB128() (uint64, error) {
 	return result, nil
 }
 
+// Unsigned variable-length quantity, big-endian 7 bit groups where high bit
+// of each byte is set if more bytes follow. Used by MIDI, git packfiles etc.
+//
+//	0x7f      => 0x7f
+//	0x81 0x00 => 0x80
+func (d *D) tryUVLQ() (uint64, error) {
+	p := d.Pos()
+	var result uint64
+
+	for {
+		b, err := d.TryUintBits(8)
+		if err != nil {
+			d.SeekAbs(p)
+			return 0, fmt.Errorf("unterminated variable-length quantity: %w", err)
+		}
+		if result > math.MaxUint64>>7 {
+			d.SeekAbs(p)
+			return 0, fmt.Errorf("overflow when reading variable-length quantity")
+		}
+		result = result<<7 | b&0x7f
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	return result, nil
+}
+
 // Signed LEB128, description from wasm spec
 //
 //	sN ::= n:byte          => n                     (if n < 2^6 && n < 2^(N-1))
@@ -286,7 +349,11 @@ func (d *D) trySLEB128() (int64, error) {
 	var b byte
 
 	for {
-		b = byte(d.U8())
+		n, err := d.TryUintBits(8)
+		if err != nil {
+			return 0, err
+		}
+		b = byte(n)
 		if shift == 63 && b != 0 && b != 0x7f {
 			return 0, fmt.Errorf("overflow when reading signed leb128, shift %d >= 63", shift)
 		}
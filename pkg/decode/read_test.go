@@ -0,0 +1,316 @@
+package decode_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/wader/fq/pkg/decode"
+	"github.com/wader/fq/pkg/scalar"
+)
+
+func TestUVLQ(t *testing.T) {
+	testCases := []struct {
+		bs       []byte
+		expected uint64
+		bits     int64
+	}{
+		{[]byte{0x00}, 0, 8},
+		{[]byte{0x7f}, 0x7f, 8},
+		{[]byte{0x81, 0x00}, 0x80, 16},
+		{[]byte{0xc0, 0x00}, 0x2000, 16},
+		{[]byte{0xff, 0xff, 0xff, 0x7f}, 0x0fffffff, 32},
+		{[]byte{0x81, 0x80, 0x80, 0x00, 0xff}, 0x200000, 32},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		if err := decodeBytes(t, tc.bs, func(d *decode.D) {
+			pn, pBits := d.PeekUVLQ()
+			if pBits != tc.bits || d.Pos() != 0 {
+				t.Errorf("%x: peek expected %d bits at pos 0, got %d bits at pos %d", tc.bs, tc.bits, pBits, d.Pos())
+			}
+			n := d.FieldUVLQ("n")
+			if n != tc.expected || pn != tc.expected {
+				t.Errorf("%x: expected %d, got %d (peek %d)", tc.bs, tc.expected, n, pn)
+			}
+			if d.Pos() != tc.bits {
+				t.Errorf("%x: expected position %d, got %d", tc.bs, tc.bits, d.Pos())
+			}
+		}); err != nil {
+			t.Errorf("%x: %s", tc.bs, err)
+		}
+	}
+}
+
+func TestUVLQUnterminated(t *testing.T) {
+	if err := decodeBytes(t, []byte{0x81, 0x80}, func(d *decode.D) {
+		if _, err := d.TryUVLQ(); err == nil {
+			t.Error("expected error")
+		}
+		if d.Pos() != 0 {
+			t.Errorf("expected position to be restored, got %d", d.Pos())
+		}
+		d.UVLQ()
+	}); err == nil {
+		t.Error("expected decode error")
+	}
+}
+
+func TestSLEB128(t *testing.T) {
+	testCases := []struct {
+		bs       []byte
+		expected int64
+	}{
+		{[]byte{0x00}, 0},
+		{[]byte{0x7f}, -1},
+		{[]byte{0x3f}, 63},
+		{[]byte{0x40}, -64},
+		{[]byte{0x80, 0x01}, 128},
+		{[]byte{0xe5, 0x8e, 0x26}, 624485},
+		{[]byte{0xc0, 0xbb, 0x78}, -123456},
+		{[]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00}, math.MaxInt64},
+		{[]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x7f}, math.MinInt64},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		if err := decodeBytes(t, tc.bs, func(d *decode.D) {
+			n := d.FieldSLEB128("n")
+			if n != tc.expected {
+				t.Errorf("%x: expected %d, got %d", tc.bs, tc.expected, n)
+			}
+			if d.Pos() != int64(len(tc.bs))*8 {
+				t.Errorf("%x: expected all bytes to be read, position %d", tc.bs, d.Pos())
+			}
+		}); err != nil {
+			t.Errorf("%x: %s", tc.bs, err)
+		}
+	}
+}
+
+func TestSLEB128Truncated(t *testing.T) {
+	if err := decodeBytes(t, []byte{0x80, 0x80}, func(d *decode.D) {
+		if _, err := d.TrySLEB128(); err == nil {
+			t.Error("expected error")
+		}
+	}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUTF16(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bs       []byte
+		fn       func(d *decode.D, name string, nBytes int, sms ...scalar.StrMapper) string
+		expected string
+	}{
+		{"bom be", []byte{0xfe, 0xff, 0x00, 'a', 0x00, 'b'}, (*decode.D).FieldUTF16, "ab"},
+		{"bom le", []byte{0xff, 0xfe, 'a', 0x00, 'b', 0x00}, (*decode.D).FieldUTF16, "ab"},
+		{"no bom", []byte{'a', 0x00, 'b', 0x00}, (*decode.D).FieldUTF16, "ab"},
+		{"le", []byte{'a', 0x00, 0x3d, 0xd8, 0x00, 0xde}, (*decode.D).FieldUTF16LE, "a\U0001f600"},
+		{"be", []byte{0x00, 'a', 0xd8, 0x3d, 0xde, 0x00}, (*decode.D).FieldUTF16BE, "a\U0001f600"},
+		{"be lone surrogate", []byte{0xd8, 0x3d, 0x00, 'a'}, (*decode.D).FieldUTF16BE, "�a"},
+		{"le lone low surrogate", []byte{0x00, 0xde, 'a', 0x00}, (*decode.D).FieldUTF16LE, "�a"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := decodeBytes(t, tc.bs, func(d *decode.D) {
+				actual := tc.fn(d, "s", len(tc.bs))
+				if actual != tc.expected {
+					t.Errorf("expected %q, got %q", tc.expected, actual)
+				}
+				if d.BitsLeft() != 0 {
+					t.Errorf("expected all bits consumed, %d left", d.BitsLeft())
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUTF8NullMax(t *testing.T) {
+	testCases := []struct {
+		name         string
+		bs           []byte
+		maxBytes     int
+		expected     string
+		expectedLeft int64
+	}{
+		{"terminated", []byte{'a', 'b', 0, 'c'}, -1, "ab", 8},
+		{"empty", []byte{0, 'a'}, -1, "", 8},
+		{"missing terminator", []byte{'a', 'b', 'c'}, -1, "abc", 0},
+		{"empty buffer", []byte{}, -1, "", 0},
+		{"max before terminator", []byte{'a', 'b', 'c', 0}, 2, "ab", 16},
+		{"terminator at max", []byte{'a', 'b', 0, 'c'}, 3, "ab", 8},
+		{"max larger than buffer", []byte{'a', 'b'}, 10, "ab", 0},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := decodeBytes(t, tc.bs, func(d *decode.D) {
+				actual := d.FieldUTF8NullMax("s", tc.maxBytes)
+				if actual != tc.expected {
+					t.Errorf("expected %q, got %q", tc.expected, actual)
+				}
+				if d.BitsLeft() != tc.expectedLeft {
+					t.Errorf("expected %d bits left, got %d", tc.expectedLeft, d.BitsLeft())
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestNibbles(t *testing.T) {
+	if err := decodeBytes(t, []byte{0x93, 0x3c}, func(d *decode.D) {
+		message := d.FieldU4("message")
+		channel := d.FieldU4("channel")
+		if message != 0x9 || channel != 0x3 {
+			t.Errorf("expected message 9 channel 3, got %d %d", message, channel)
+		}
+		for i, name := range []string{"message", "channel"} {
+			r := d.FieldGet(name).Range
+			if r.Start != int64(i*4) || r.Len != 4 {
+				t.Errorf("%s: expected range %d+4, got %d+%d", name, i*4, r.Start, r.Len)
+			}
+		}
+		if n := d.FieldU8("note"); n != 0x3c {
+			t.Errorf("expected note 0x3c, got %x", n)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExplicitEndian(t *testing.T) {
+	bs := []byte{
+		0x01, 0x02, // u16
+		0x01, 0x02, 0x03, // u24
+		0x01, 0x02, 0x03, 0x04, // u32
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, // u64
+		0xfe, 0xff, // s16
+		0xfe, 0xff, 0xff, // s24
+		0xfe, 0xff, 0xff, 0xff, // s32
+		0xfe, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // s64
+	}
+	testCases := []struct {
+		name     string
+		endian   decode.Endian
+		fn       func(d *decode.D) []any
+		expected []any
+	}{
+		{
+			name:   "le in be",
+			endian: decode.BigEndian,
+			fn: func(d *decode.D) []any {
+				return []any{
+					d.FieldU16LE("u16"), d.FieldU24LE("u24"), d.FieldU32LE("u32"), d.FieldU64LE("u64"),
+					d.FieldS16LE("s16"), d.FieldS24LE("s24"), d.FieldS32LE("s32"), d.FieldS64LE("s64"),
+				}
+			},
+			expected: []any{
+				uint64(0x0201), uint64(0x030201), uint64(0x04030201), uint64(0x0807060504030201),
+				int64(-2), int64(-2), int64(-2), int64(-2),
+			},
+		},
+		{
+			name:   "be in le",
+			endian: decode.LittleEndian,
+			fn: func(d *decode.D) []any {
+				return []any{
+					d.FieldU16BE("u16"), d.FieldU24BE("u24"), d.FieldU32BE("u32"), d.FieldU64BE("u64"),
+					d.FieldS16BE("s16"), d.FieldS24BE("s24"), d.FieldS32BE("s32"), d.FieldS64BE("s64"),
+				}
+			},
+			expected: []any{
+				uint64(0x0102), uint64(0x010203), uint64(0x01020304), uint64(0x0102030405060708),
+				int64(-257), int64(-65537), int64(-16777217), int64(-72057594037927937),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := decodeBytes(t, bs, func(d *decode.D) {
+				d.Endian = tc.endian
+				actual := tc.fn(d)
+				for i, e := range tc.expected {
+					if actual[i] != e {
+						t.Errorf("%d: expected %v, got %v", i, e, actual[i])
+					}
+				}
+				if d.Endian != tc.endian {
+					t.Errorf("expected endian to be unchanged")
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestF16(t *testing.T) {
+	testCases := []struct {
+		bits     uint16
+		expected float64
+	}{
+		{0x0000, 0},
+		{0x3c00, 1},
+		{0xc000, -2},
+		{0x3555, 0.333251953125},
+		{0x7bff, 65504},                 // max normal
+		{0x0400, 6.103515625e-05},       // min normal
+		{0x0001, 5.960464477539063e-08}, // min subnormal
+		{0x03ff, 6.097555160522461e-05}, // max subnormal
+		{0x7c00, math.Inf(1)},
+		{0xfc00, math.Inf(-1)},
+		{0x7e00, math.NaN()},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("%04x", tc.bits), func(t *testing.T) {
+			if err := decodeBytes(t, []byte{byte(tc.bits >> 8), byte(tc.bits)}, func(d *decode.D) {
+				actual := d.FieldF16("f")
+				if math.IsNaN(tc.expected) && !math.IsNaN(actual) || !math.IsNaN(tc.expected) && actual != tc.expected {
+					t.Errorf("expected %v, got %v", tc.expected, actual)
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestUTF8TrimPadding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bs       []byte
+		expected string
+	}{
+		{"unpadded", []byte("abcd"), "abcd"},
+		{"space padded", []byte("ab  "), "ab"},
+		{"null padded", []byte("ab\x00\x00"), "ab"},
+		{"mixed padding", []byte("ab \x00"), "ab"},
+		{"fully padded", []byte("  \x00\x00"), ""},
+		{"leading space kept", []byte(" ab "), " ab"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := decodeBytes(t, tc.bs, func(d *decode.D) {
+				actual := d.FieldUTF8("s", len(tc.bs), scalar.ActualTrimPadding)
+				if actual != tc.expected {
+					t.Errorf("expected %q, got %q", tc.expected, actual)
+				}
+				if r := d.FieldGet("s").Range; r.Start != 0 || r.Len != int64(len(tc.bs))*8 {
+					t.Errorf("expected range to cover all %d bytes, got %v", len(tc.bs), r)
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"hash"
+	"hash/crc32"
 
 	"github.com/wader/fq/internal/bitioex"
 	"github.com/wader/fq/pkg/bitio"
+	"github.com/wader/fq/pkg/checksum"
 	"github.com/wader/fq/pkg/scalar"
 )
 
@@ -174,3 +177,22 @@ func (d *D) UintValidateBEBytes(bss ...[]byte) scalar.UintMapper {
 		return UintAssertBytes(s, false, BigEndian, bss...)
 	})
 }
+
+// FieldUintChecksum reads a checksum field with size of h and validates it
+// against h computed over nBits bits starting at firstBit
+func (d *D) FieldUintChecksum(name string, h hash.Hash, firstBit int64, nBits int64, sms ...scalar.UintMapper) uint64 {
+	d.CopyBits(h, d.BitBufRange(firstBit, nBits))
+	return d.FieldU(name, h.Size()*8, append([]scalar.UintMapper{d.UintValidateBytes(h.Sum(nil))}, sms...)...)
+}
+
+// FieldCRC32IEEE reads a 32 bit CRC-32 (IEEE) checksum field and validates it
+// against nBits bits starting at firstBit
+func (d *D) FieldCRC32IEEE(name string, firstBit int64, nBits int64, sms ...scalar.UintMapper) uint64 {
+	return d.FieldUintChecksum(name, crc32.NewIEEE(), firstBit, nBits, sms...)
+}
+
+// FieldCRC16CCITT reads a 16 bit CRC-16/CCITT-FALSE checksum field and validates it
+// against nBits bits starting at firstBit
+func (d *D) FieldCRC16CCITT(name string, firstBit int64, nBits int64, sms ...scalar.UintMapper) uint64 {
+	return d.FieldUintChecksum(name, &checksum.CRC{Bits: 16, Current: 0xffff, Table: checksum.CCITT16Table}, firstBit, nBits, sms...)
+}
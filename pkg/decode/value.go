@@ -3,6 +3,8 @@ package decode
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/wader/fq/pkg/bitio"
 	"github.com/wader/fq/pkg/ranges"
@@ -31,6 +33,7 @@ type Value struct {
 	Format      *Format // TODO: rework
 	Description string
 	Err         error
+	IsSynthetic bool // computed by decoder, not read from input
 }
 
 type WalkFn func(v *Value, rootV *Value, depth int, rootDepth int) error
@@ -173,6 +176,33 @@ func (v *Value) Errors() []error {
 	return errs
 }
 
+// path returns jq like path, ex .a.b[1], usable while decoding as index is
+// looked up in parent compound instead of using Index that is set in post-process
+func (v *Value) path() string {
+	var parts []string
+	for ; v.Parent != nil; v = v.Parent {
+		c, ok := v.Parent.V.(*Compound)
+		if !ok || !c.IsArray {
+			parts = append(parts, "."+v.Name)
+			continue
+		}
+		for i := len(c.Children) - 1; i >= 0; i-- {
+			if c.Children[i] == v {
+				parts = append(parts, "["+strconv.Itoa(i)+"]")
+				break
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return "."
+	}
+	var sb strings.Builder
+	for i := len(parts) - 1; i >= 0; i-- {
+		sb.WriteString(parts[i])
+	}
+	return sb.String()
+}
+
 func (v *Value) InnerRange() ranges.Range {
 	if v.IsRoot {
 		return ranges.Range{Start: 0, Len: v.Range.Len}
@@ -267,7 +297,7 @@ func (v *Value) Remove() error {
 			if _, ok := fv.ByName[v.Name]; !ok {
 				return fmt.Errorf("d not in parent ByName")
 			}
-			delete(fv.ByName, p.Name)
+			delete(fv.ByName, v.Name)
 		}
 		found := false
 		var cs []*Value
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/fs"
 	"math/big"
+	"strings"
 
 	"github.com/wader/fq/internal/aheadreadseeker"
 	"github.com/wader/fq/internal/bitioex"
@@ -22,6 +23,9 @@ import (
 func init() {
 	RegisterFunc1("_tobits", (*Interp)._toBits)
 	RegisterFunc0("open", (*Interp)._open)
+	RegisterFunc1("_peek_bytes", (*Interp)._peekBytes)
+	RegisterFunc2("slice", (*Interp).slice)
+	RegisterFunc1("bits", (*Interp).bits)
 }
 
 type ToBinary interface {
@@ -144,6 +148,134 @@ func (i *Interp) _toBits(c any, opts toBitsOpts) any {
 	return bb
 }
 
+// bytes following input in its underlying buffer, truncated at end of buffer
+func (i *Interp) _peekBytes(c any, nBytes int) any {
+	if nBytes < 0 {
+		return fmt.Errorf("peek_bytes length must be >= 0 (%d)", nBytes)
+	}
+
+	bv, err := toBinary(c)
+	if err != nil {
+		return err
+	}
+	brLen, err := bitioex.Len(bv.br)
+	if err != nil {
+		return err
+	}
+
+	start := bv.r.Stop()
+	nBits := int64(nBytes) * 8
+	if start+nBits > brLen {
+		nBits = brLen - start
+	}
+
+	return Binary{
+		br:   bv.br,
+		r:    ranges.Range{Start: start, Len: nBits},
+		unit: 8,
+	}
+}
+
+// slice returns a byte unit binary for length bytes at byte offset start of input,
+// negative start is relative to the end
+func (i *Interp) slice(c any, start int, length int) any {
+	bv, err := toBinary(c)
+	if err != nil {
+		return err
+	}
+	if length < 0 {
+		return fmt.Errorf("slice length must be >= 0 (%d)", length)
+	}
+
+	size := bv.r.Len / 8
+	s := int64(start)
+	if s < 0 {
+		s += size
+	}
+	if s < 0 || s+int64(length) > size {
+		return fmt.Errorf("slice start %d length %d outside binary of size %d bytes", start, length, size)
+	}
+
+	return Binary{
+		br:   bv.br,
+		r:    ranges.Range{Start: bv.r.Start + s*8, Len: int64(length) * 8},
+		unit: 8,
+	}
+}
+
+// bitsLiteralWidth parses a "0b" or "0x" prefixed literal, width is number of digits times bits per digit
+func bitsLiteralWidth(s string) (*big.Int, int, error) {
+	var base int
+	var digitBits int
+	switch {
+	case strings.HasPrefix(s, "0b"):
+		base, digitBits = 2, 1
+	case strings.HasPrefix(s, "0x"):
+		base, digitBits = 16, 4
+	default:
+		return nil, 0, fmt.Errorf("bits literal %q must start with 0b or 0x, use {value: ..., width: ...} for other forms", s)
+	}
+	digits := s[2:]
+	bi, ok := new(big.Int).SetString(digits, base)
+	if !ok || digits == "" {
+		return nil, 0, fmt.Errorf("bits literal %q is malformed", s)
+	}
+	return bi, len(digits) * digitBits, nil
+}
+
+// bits creates binary from a literal, "0b1010", "0x0a" or {value: 10, width: 5}
+// where value can also be a "0b", "0x" or decimal string
+func (i *Interp) bits(_ any, v any) any {
+	var bi *big.Int
+	var width int
+
+	switch v := v.(type) {
+	case string:
+		var err error
+		if bi, width, err = bitsLiteralWidth(v); err != nil {
+			return err
+		}
+	case map[string]any:
+		wbi, err := toBigInt(v["width"])
+		if err != nil || !wbi.IsInt64() {
+			return fmt.Errorf("bits width must be a number")
+		}
+		width = int(wbi.Int64())
+		switch vv := v["value"].(type) {
+		case string:
+			var ok bool
+			if bi, ok = new(big.Int).SetString(vv, 0); !ok {
+				return fmt.Errorf("bits value %q is malformed", vv)
+			}
+		default:
+			if bi, err = toBigInt(vv); err != nil {
+				return fmt.Errorf("bits value must be a number or string")
+			}
+		}
+	default:
+		return gojqex.FuncTypeError{Name: "bits", V: v}
+	}
+
+	if width <= 0 {
+		return fmt.Errorf("bits width must be > 0 (%d)", width)
+	}
+	if bi.Sign() < 0 {
+		return fmt.Errorf("bits value must be >= 0 (%s)", bi)
+	}
+	if bi.BitLen() > width {
+		return fmt.Errorf("bits value %s does not fit in %d bits", bi, width)
+	}
+
+	// left align value as bit reader reads most significant bit first
+	nBytes := (width + 7) / 8
+	bs := new(big.Int).Lsh(bi, uint(nBytes*8-width)).FillBytes(make([]byte, nBytes))
+	bb, err := NewBinaryFromBitReader(bitio.NewBitReader(bs, int64(width)), 1, 0)
+	if err != nil {
+		return err
+	}
+	return bb
+}
+
 type openFile struct {
 	Binary
 	filename   string
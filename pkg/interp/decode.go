@@ -177,9 +177,12 @@ func (i *Interp) _toValue(c any, om map[string]any) any {
 }
 
 type decodeOpts struct {
-	Force    bool
-	Progress string
-	Remain   map[string]any `mapstruct:",remain"`
+	Force          bool
+	MaxDepth       int
+	MaxFields      int
+	MaxFormatDepth int
+	Progress       string
+	Remain         map[string]any `mapstruct:",remain"`
 }
 
 func (i *Interp) _decode(c any, format string, opts decodeOpts) any {
@@ -228,7 +231,7 @@ func (i *Interp) _decode(c any, format string, opts decodeOpts) any {
 
 	bv, err := toBinary(c)
 	if err != nil {
-		return err
+		return gojqex.FuncTypeError{Name: "decode", V: c}
 	}
 
 	formatName, err := toString(format)
@@ -237,16 +240,26 @@ func (i *Interp) _decode(c any, format string, opts decodeOpts) any {
 	}
 	decodeGroup, err := i.Registry.Group(formatName)
 	if err != nil {
+		// unknown names are decoded using the probe_args group with the name as
+		// decode_group, report the name that was asked for if there is no such group
+		if isProbeArgs, _ := opts.Remain["is_probe_args"].(bool); isProbeArgs {
+			if name, ok := opts.Remain["decode_group"].(string); ok {
+				return fmt.Errorf("format group %q not found", name)
+			}
+		}
 		return err
 	}
 
 	dv, formatOut, err := decode.Decode(i.EvalInstance.Ctx, bv.br, decodeGroup,
 		decode.Options{
-			IsRoot:      true,
-			FillGaps:    true,
-			Force:       opts.Force,
-			Range:       bv.r,
-			Description: filename,
+			IsRoot:         true,
+			FillGaps:       true,
+			Force:          opts.Force,
+			MaxDepth:       opts.MaxDepth,
+			MaxFields:      opts.MaxFields,
+			MaxFormatDepth: opts.MaxFormatDepth,
+			Range:          bv.r,
+			Description:    filename,
 			ParseOptsFn: func(init any) any {
 				v, err := copystructure.Copy(init)
 				if err != nil {
@@ -382,58 +395,58 @@ func makeDecodeValueOut(dv *decode.Value, kind decodeValueKind, out any) any {
 						return gojqex.String([]rune(buf.String())), nil
 					},
 				},
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 				isRaw:           true,
 			}
 		case bool:
 			return decodeValue{
 				JQValue:         gojqex.Boolean(vvv),
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case int:
 			return decodeValue{
 				JQValue:         gojqex.Number{V: vvv},
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case int64:
 			return decodeValue{
 				JQValue:         gojqex.Number{V: big.NewInt(vvv)},
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case uint64:
 			return decodeValue{
 				JQValue:         gojqex.Number{V: new(big.Int).SetUint64(vvv)},
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case float64:
 			return decodeValue{
 				JQValue:         gojqex.Number{V: vvv},
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case string:
 			return decodeValue{
 				JQValue:         gojqex.String(vvv),
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case []any:
 			return decodeValue{
 				JQValue:         gojqex.Array(vvv),
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case map[string]any:
 			return decodeValue{
 				JQValue:         gojqex.Object(vvv),
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case nil:
 			return decodeValue{
 				JQValue:         gojqex.Null{},
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case *big.Int:
 			return decodeValue{
 				JQValue:         gojqex.Number{V: vvv},
-				decodeValueBase: decodeValueBase{dv: dv},
+				decodeValueBase: decodeValueBase{dv: dv, out: out},
 			}
 		case Binary:
 			return vvv
@@ -575,6 +588,8 @@ func (dvb decodeValueBase) JQValueKey(name string) any {
 		var formatErr decode.FormatError
 		if errors.As(dv.Err, &formatErr) {
 			return formatErr.Value()
+		} else if dv.Err != nil {
+			return map[string]any{"error": dv.Err.Error()}
 		}
 		return nil
 	case "_format":
@@ -31,6 +31,7 @@ func decoratorFromOptions(opts Options) Decorator {
 		d.Number = ansi.FromString(colors["number"])
 		d.String = ansi.FromString(colors["string"])
 		d.ObjectKey = ansi.FromString(colors["objectkey"])
+		d.Synthetic = ansi.FromString(colors["synthetic"])
 		d.Array = ansi.FromString(colors["array"])
 		d.Object = ansi.FromString(colors["object"])
 
@@ -98,6 +99,7 @@ type Decorator struct {
 	Number    ansi.Code
 	String    ansi.Code
 	ObjectKey ansi.Code
+	Synthetic ansi.Code
 	Array     ansi.Code
 	Object    ansi.Code
 
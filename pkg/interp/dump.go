@@ -1,6 +1,7 @@
 package interp
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -45,6 +46,14 @@ func isCompound(v *decode.Value) bool {
 	}
 }
 
+// isEmpty returns true for values with zero length range and arrays without elements
+func isEmpty(v *decode.Value) bool {
+	if dc, ok := v.V.(*decode.Compound); ok && dc.IsArray && len(dc.Children) == 0 {
+		return true
+	}
+	return v.Range.Len == 0
+}
+
 type dumpCtx struct {
 	opts        *Options
 	buf         []byte
@@ -92,9 +101,13 @@ func dumpEx(v *decode.Value, ctx *dumpCtx, depth int, rootV *decode.Value, rootD
 		nameV = v.Parent
 		name = ""
 	}
-	if depth == 0 {
+	switch {
+	case depth == 0:
 		name = valuePathExprDecorated(nameV, deco)
-	} else {
+	case v.IsSynthetic:
+		// computed values has no bytes so color differently to not be confused with read values
+		name = deco.Synthetic.Wrap(name)
+	default:
 		name = deco.ObjectKey.Wrap(name)
 	}
 
@@ -337,12 +350,19 @@ func dumpEx(v *decode.Value, ctx *dumpCtx, depth int, rootV *decode.Value, rootD
 }
 
 func dump(v *decode.Value, w io.Writer, opts *Options) error {
+	if opts.Flat {
+		return dumpFlat(v, w, opts)
+	}
+
 	maxAddrIndentWidth := 0
 	makeWalkFn := func(fn decode.WalkFn) decode.WalkFn {
 		return func(v *decode.Value, rootV *decode.Value, depth int, rootDepth int) error {
 			if opts.Depth != 0 && depth > opts.Depth {
 				return decode.ErrWalkSkipChildren
 			}
+			if opts.HideEmpty && depth != 0 && isEmpty(v) {
+				return decode.ErrWalkSkipChildren
+			}
 
 			return fn(v, rootV, depth, rootDepth)
 		}
@@ -372,16 +392,31 @@ func dump(v *decode.Value, w io.Writer, opts *Options) error {
 	// 	treeColumnWidth = mathex.Max(0, opts.Width-(addrColumnWidth+hexColumnWidth+asciiColumnWidth+3 /* bars */))
 	// }
 
-	cw := columnwriter.New(
-		w,
-		&columnwriter.MultiLineColumn{Width: addrColumnWidth, LenFn: displayLenFn, SliceFn: displayTruncateFn},
-		columnwriter.BarColumn(opts.Decorator.Column),
-		&columnwriter.MultiLineColumn{Width: hexColumnWidth, LenFn: displayLenFn, SliceFn: displayTruncateFn},
-		columnwriter.BarColumn(opts.Decorator.Column),
-		&columnwriter.MultiLineColumn{Width: asciiColumnWidth, LenFn: displayLenFn, SliceFn: displayTruncateFn},
-		columnwriter.BarColumn(opts.Decorator.Column),
-		&columnwriter.MultiLineColumn{Width: treeColumnWidth, Wrap: false, LenFn: displayLenFn, SliceFn: displayTruncateFn},
-	)
+	var cw *columnwriter.Writer
+	if opts.SkipHexdump {
+		// keep column indexes but only show tree
+		cw = columnwriter.New(
+			w,
+			columnwriter.DiscardColumn{},
+			columnwriter.DiscardColumn{},
+			columnwriter.DiscardColumn{},
+			columnwriter.DiscardColumn{},
+			columnwriter.DiscardColumn{},
+			columnwriter.DiscardColumn{},
+			&columnwriter.MultiLineColumn{Width: treeColumnWidth, Wrap: false, LenFn: displayLenFn, SliceFn: displayTruncateFn},
+		)
+	} else {
+		cw = columnwriter.New(
+			w,
+			&columnwriter.MultiLineColumn{Width: addrColumnWidth, LenFn: displayLenFn, SliceFn: displayTruncateFn},
+			columnwriter.BarColumn(opts.Decorator.Column),
+			&columnwriter.MultiLineColumn{Width: hexColumnWidth, LenFn: displayLenFn, SliceFn: displayTruncateFn},
+			columnwriter.BarColumn(opts.Decorator.Column),
+			&columnwriter.MultiLineColumn{Width: asciiColumnWidth, LenFn: displayLenFn, SliceFn: displayTruncateFn},
+			columnwriter.BarColumn(opts.Decorator.Column),
+			&columnwriter.MultiLineColumn{Width: treeColumnWidth, Wrap: false, LenFn: displayLenFn, SliceFn: displayTruncateFn},
+		)
+	}
 
 	buf := make([]byte, 32*1024)
 
@@ -409,6 +444,69 @@ func dump(v *decode.Value, w io.Writer, opts *Options) error {
 	}))
 }
 
+// dumpFlat writes one line per non-compound value with address, first bytes and path.
+// No tree indentation so that output from two inputs can be diffed line by line.
+func dumpFlat(v *decode.Value, w io.Writer, opts *Options) error {
+	deco := opts.Decorator
+
+	addrWidth := 0
+	_ = v.WalkPreOrder(func(v *decode.Value, _ *decode.Value, _ int, _ int) error {
+		addrWidth = mathex.Max(
+			addrWidth,
+			mathex.DigitsInBase(bitio.BitsByteCount(v.InnerRange().Stop()), true, opts.Addrbase),
+		)
+		return nil
+	})
+	hexWidth := opts.LineBytes*3 - 1
+
+	return v.WalkPreOrder(func(v *decode.Value, _ *decode.Value, _ int, _ int) error {
+		s, ok := v.V.(Scalarable)
+		if !ok || (s.ScalarIsGap() && opts.SkipGaps) {
+			return nil
+		}
+		innerRange := v.InnerRange()
+		if innerRange.Len == 0 {
+			return nil
+		}
+
+		displayBits := mathex.Min(innerRange.Len, int64(opts.LineBytes)*8)
+		br, err := bitioex.Range(v.RootReader, innerRange.Start, displayBits)
+		if err != nil {
+			return err
+		}
+		bb := &bytes.Buffer{}
+		if _, err := bitioex.CopyBits(bb, br); err != nil {
+			return err
+		}
+
+		var hexPairs []string
+		for _, b := range bb.Bytes() {
+			hexPairs = append(hexPairs, deco.ByteColor(b).Wrap(hexpairwriter.Pair(b)))
+		}
+		hexLen := mathex.Max(0, bb.Len()*3-1)
+		truncated := " "
+		if displayBits < innerRange.Len {
+			truncated = "*"
+		}
+		// bit offset suffix for non-byte aligned values
+		addrBits := "  "
+		if innerRange.Start%8 != 0 {
+			addrBits = "." + strconv.FormatInt(innerRange.Start%8, opts.Addrbase)
+		}
+
+		_, err = fmt.Fprintf(w, "%s%s%s %s%s%s %s\n",
+			deco.DumpAddr.F(mathex.PadFormatInt(innerRange.Start/8, opts.Addrbase, true, addrWidth)),
+			addrBits,
+			deco.Column,
+			strings.Join(hexPairs, " "),
+			indentStr(mathex.Max(0, hexWidth-hexLen)),
+			truncated,
+			valuePathExprDecorated(v, deco),
+		)
+		return err
+	})
+}
+
 func hexdump(w io.Writer, bv Binary, opts *Options) error {
 	br, err := bitioex.Range(bv.br, bv.r.Start, bv.r.Len)
 	if err != nil {
@@ -417,6 +515,7 @@ func hexdump(w io.Writer, bv Binary, opts *Options) error {
 
 	// TODO: hack
 	opts.Verbose = true
+	opts.Flat = false
 	return dump(
 		&decode.Value{
 			// TODO: hack
@@ -72,6 +72,8 @@ func init() {
 	RegisterIter1("_display", (*Interp)._display)
 	RegisterFunc0("_can_display", (*Interp)._canDisplay)
 	RegisterIter1("_hexdump", (*Interp)._hexdump)
+	RegisterFunc1("_tohexdump", (*Interp)._toHexdump)
+	RegisterFunc1("_totree", (*Interp)._toTree)
 	RegisterIter1("_print_color_json", (*Interp)._printColorJSON)
 
 	RegisterFunc0("_is_completing", (*Interp)._isCompleting)
@@ -678,6 +680,42 @@ func (i *Interp) _hexdump(c any, v any) gojq.Iter {
 	return gojq.NewIter()
 }
 
+func (i *Interp) _toHexdump(c any, v any) any {
+	opts, err := OptionsFromValue(v)
+	if err != nil {
+		return err
+	}
+
+	bv, err := toBinary(c)
+	if err != nil {
+		return gojqex.FuncTypeError{Name: "tohexdump", V: c}
+	}
+	sb := &strings.Builder{}
+	if err := hexdump(sb, bv, opts); err != nil {
+		return err
+	}
+
+	return sb.String()
+}
+
+func (i *Interp) _toTree(c any, v any) any {
+	opts, err := OptionsFromValue(v)
+	if err != nil {
+		return err
+	}
+
+	dv, ok := c.(DecodeValue)
+	if !ok {
+		return gojqex.FuncTypeError{Name: "tree", V: c}
+	}
+	sb := &strings.Builder{}
+	if err := dump(dv.DecodeValue(), sb, opts); err != nil {
+		return err
+	}
+
+	return sb.String()
+}
+
 func (i *Interp) _printColorJSON(c any, v any) gojq.Iter {
 	opts, err := OptionsFromValue(v)
 	if err != nil {
@@ -1047,6 +1085,9 @@ type Options struct {
 	Addrbase     int
 	Sizebase     int
 	SkipGaps     bool
+	HideEmpty    bool
+	SkipHexdump  bool
+	Flat         bool
 
 	Decorator    Decorator
 	BitsFormatFn func(br bitio.ReaderAtSeeker) (any, error)
@@ -1101,14 +1142,7 @@ func bitsFormatFnFromOptions(opts Options) (func(br bitio.ReaderAtSeeker) (any,
 			return b.String(), nil
 		}, nil
 	case "truncate":
-		// TODO: configure
-		return func(br bitio.ReaderAtSeeker) (any, error) {
-			b := &bytes.Buffer{}
-			if _, err := bitioex.CopyBits(b, bitio.NewLimitReader(br, 1024*8)); err != nil {
-				return "", err
-			}
-			return b.String(), nil
-		}, nil
+		return truncateBitsFormatFn(1024), nil
 	case "string":
 		return func(br bitio.ReaderAtSeeker) (any, error) {
 			b := &bytes.Buffer{}
@@ -1133,10 +1167,26 @@ func bitsFormatFnFromOptions(opts Options) (func(br bitio.ReaderAtSeeker) (any,
 			return fmt.Sprintf("<%s>%s", mathex.Bits(brLen).StringByteBits(opts.Sizebase), b.String()), nil
 		}, nil
 	default:
+		// truncate:<bytes>
+		if strings.HasPrefix(opts.BitsFormat, "truncate:") {
+			if l, err := strconv.Atoi(strings.TrimPrefix(opts.BitsFormat, "truncate:")); err == nil && l >= 0 {
+				return truncateBitsFormatFn(l), nil
+			}
+		}
 		return nil, fmt.Errorf("invalid bits format %q", opts.BitsFormat)
 	}
 }
 
+func truncateBitsFormatFn(n int) func(br bitio.ReaderAtSeeker) (any, error) {
+	return func(br bitio.ReaderAtSeeker) (any, error) {
+		b := &bytes.Buffer{}
+		if _, err := bitioex.CopyBits(b, bitio.NewLimitReader(br, int64(n)*8)); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+}
+
 func (i *Interp) lookupState(key string) any {
 	if i.state == nil {
 		return nil
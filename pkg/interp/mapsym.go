@@ -0,0 +1,140 @@
+package interp
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/wader/fq/internal/gojqex"
+	"github.com/wader/fq/pkg/scalar"
+)
+
+func init() {
+	RegisterFunc1("mapsym", (*Interp).mapSym)
+	RegisterFunc1("_symtable_source", (*Interp)._symTableSource)
+}
+
+var symTableNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z_0-9]*$`)
+
+// _symTableSource finds symtables/<name>.jq in include paths and then in config directory
+func (i *Interp) _symTableSource(c any, name string) any {
+	if !symTableNameRe.MatchString(name) {
+		return fmt.Errorf("invalid symbol table name %q", name)
+	}
+
+	filename := path.Join("symtables", name+".jq")
+	for _, p := range []string{filename, "@config/" + filename} {
+		pr, err := i.lookupPathResolver(p)
+		if err != nil {
+			return err
+		}
+		f, absPath, err := pr.open(strings.TrimPrefix(p, pr.prefix))
+		if err != nil {
+			continue
+		}
+		b, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		return map[string]any{
+			"filename": absPath,
+			"source":   string(b),
+		}
+	}
+
+	return fmt.Errorf("symbol table %q not found", name)
+}
+
+// mapSymKey parses object key k as the same type as actual
+func mapSymKey(actual any, k string) (any, error) {
+	switch actual.(type) {
+	case uint64:
+		return strconv.ParseUint(k, 0, 64)
+	case int64:
+		return strconv.ParseInt(k, 0, 64)
+	case float64:
+		return strconv.ParseFloat(k, 64)
+	case bool:
+		return strconv.ParseBool(k)
+	case string:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("can't map actual of type %T", actual)
+	}
+}
+
+// mapSym returns a copy of a scalar decode value with symbolic value looked up
+// from m using actual value as key. Keys are parsed as the actual type and keys
+// parsing to the same value is an error. Only unsigned, signed, float, boolean and
+// string scalars are supported, not raw bits, big integers or any values.
+func (i *Interp) mapSym(c any, m map[string]any) any {
+	dv, ok := c.(DecodeValue)
+	if !ok {
+		return gojqex.FuncTypeError{Name: "mapsym", V: c}
+	}
+	v := dv.DecodeValue()
+	switch v.V.(type) {
+	case *scalar.Uint, *scalar.Sint, *scalar.Flt, *scalar.Bool, *scalar.Str:
+	default:
+		return gojqex.FuncTypeError{Name: "mapsym", V: c}
+	}
+	s := v.V.(Scalarable)
+
+	// sorted to report same duplicate each time
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	actual := s.ScalarActual()
+	var sym any
+	seen := map[any]string{}
+	for _, k := range keys {
+		ka, err := mapSymKey(actual, k)
+		if err != nil {
+			return fmt.Errorf("mapsym key %q: %w", k, err)
+		}
+		if pk, ok := seen[ka]; ok {
+			return fmt.Errorf("mapsym keys %q and %q are the same value", pk, k)
+		}
+		seen[ka] = k
+		if ka == actual {
+			sym = m[k]
+		}
+	}
+	if sym == nil {
+		return c
+	}
+
+	nv := *v
+	switch s := v.V.(type) {
+	case *scalar.Uint:
+		ns := *s
+		ns.Sym = sym
+		nv.V = &ns
+	case *scalar.Sint:
+		ns := *s
+		ns.Sym = sym
+		nv.V = &ns
+	case *scalar.Flt:
+		ns := *s
+		ns.Sym = sym
+		nv.V = &ns
+	case *scalar.Bool:
+		ns := *s
+		ns.Sym = sym
+		nv.V = &ns
+	case *scalar.Str:
+		ns := *s
+		ns.Sym = sym
+		nv.V = &ns
+	}
+
+	return makeDecodeValue(&nv, decodeValueValue)
+}
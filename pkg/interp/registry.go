@@ -1,7 +1,6 @@
 package interp
 
 import (
-	"errors"
 	"fmt"
 	"io/fs"
 	"sync"
@@ -103,7 +102,7 @@ func (r *Registry) Group(name string) (*decode.Group, error) {
 	if g, ok := r.groups[name]; ok {
 		return g, nil
 	}
-	return nil, errors.New("format group not found")
+	return nil, fmt.Errorf("format group %q not found", name)
 }
 
 func (r *Registry) MustGroup(name string) *decode.Group {
@@ -8,6 +8,7 @@ package scalar
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -80,8 +81,15 @@ func StrActualTrim(cutset string) StrActualFn {
 	return StrActualFn(func(a string) string { return strings.Trim(a, cutset) })
 }
 
+func StrActualTrimRight(cutset string) StrActualFn {
+	return StrActualFn(func(a string) string { return strings.TrimRight(a, cutset) })
+}
+
 var ActualTrimSpace = StrActualFn(strings.TrimSpace)
 
+// ActualTrimPadding trims trailing null and space padding of fixed length strings
+var ActualTrimPadding = StrActualTrimRight(" \x00")
+
 func strMapToSym(fn func(s string) (any, error), try bool) StrMapper {
 	return StrFn(func(s Str) (Str, error) {
 		ts := strings.TrimSpace(s.Actual)
@@ -165,6 +173,19 @@ func (rs SRangeToScalar) MapSint(s Sint) (Sint, error) {
 }
 
 func RawSym(s BitBuf, nBytes int, fn func(b []byte) string) (BitBuf, error) {
+	return rawSym(s, nBytes, func(b []byte) any { return fn(b) })
+}
+
+// RawSymFn sets symbolic value to fn called with the raw bytes, useful to show an
+// interpretation of raw bits like a packed fixed-point number. Last byte is zero
+// padded if not byte aligned.
+func RawSymFn(fn func(b []byte) any) BitBufMapper {
+	return BitBufFn(func(s BitBuf) (BitBuf, error) {
+		return rawSym(s, -1, fn)
+	})
+}
+
+func rawSym(s BitBuf, nBytes int, fn func(b []byte) any) (BitBuf, error) {
 	br := s.Actual
 	brLen, err := bitioex.Len(br)
 	if err != nil {
@@ -225,6 +246,24 @@ func (m RawBytesMap) MapBitBuf(s BitBuf) (BitBuf, error) {
 	return s, nil
 }
 
+// UintFixedPoint sets symbolic value to actual as a fixed-point number with fracBits
+// fraction bits, ex: 16.16 read using FieldU32 is UintFixedPoint(16)
+func UintFixedPoint(fracBits int) UintFn {
+	return UintFn(func(s Uint) (Uint, error) {
+		s.Sym = math.Ldexp(float64(s.Actual), -fracBits)
+		return s, nil
+	})
+}
+
+// SintFixedPoint sets symbolic value to actual as a signed fixed-point number with fracBits
+// fraction bits, ex: signed 8.8 read using FieldS16 is SintFixedPoint(8)
+func SintFixedPoint(fracBits int) SintFn {
+	return SintFn(func(s Sint) (Sint, error) {
+		s.Sym = math.Ldexp(float64(s.Actual), -fracBits)
+		return s, nil
+	})
+}
+
 var unixTimeEpochDate = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
 
 func UintActualDate(epoch time.Time, format string) UintFn {